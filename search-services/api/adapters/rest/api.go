@@ -1,11 +1,15 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"yadro.com/course/api/core"
 )
@@ -15,24 +19,114 @@ type pingReply struct {
 }
 
 type statsReply struct {
-	WordsTotal    int `json:"words_total"`
-	WordsUnique   int `json:"words_unique"`
-	ComicsFetched int `json:"comics_fetched"`
-	ComicsTotal   int `json:"comics_total"`
+	WordsTotal     int `json:"words_total"`
+	WordsUnique    int `json:"words_unique"`
+	ComicsFetched  int `json:"comics_fetched"`
+	ComicsNotFound int `json:"comics_not_found"`
+	ComicsFailed   int `json:"comics_failed"`
+	ComicsTotal    int `json:"comics_total"`
 }
 
 type statusReply struct {
-	Status string `json:"status"`
+	Status   string         `json:"status"`
+	NextRun  *time.Time     `json:"next_run,omitempty"`
+	Progress *progressReply `json:"progress,omitempty"`
+}
+
+type progressReply struct {
+	JobID        int64      `json:"job_id"`
+	Total        int        `json:"total"`
+	Done         int        `json:"done"`
+	Failed       int        `json:"failed"`
+	Placeholders int        `json:"placeholders"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	ETA          *time.Time `json:"eta,omitempty"`
+}
+
+type jobReply struct {
+	ID           int64      `json:"id"`
+	Trigger      string     `json:"trigger"`
+	State        string     `json:"state"`
+	Total        int        `json:"total"`
+	Done         int        `json:"done"`
+	Failed       int        `json:"failed"`
+	Placeholders int        `json:"placeholders"`
+	Errors       []string   `json:"errors,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	DurationMS   int64      `json:"duration_ms"`
+}
+
+type updateReply struct {
+	Status string   `json:"status"`
+	Job    jobReply `json:"job"`
+}
+
+type jobsReply struct {
+	Jobs []jobReply `json:"jobs"`
+}
+
+type failureReply struct {
+	ID            int       `json:"id"`
+	Class         string    `json:"class"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	LastFailedAt  time.Time `json:"last_failed_at"`
+	NextRetryAt   time.Time `json:"next_retry_at"`
+}
+
+type failuresReply struct {
+	Failures []failureReply `json:"failures"`
 }
 
 type comicsReply struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID           int      `json:"id"`
+	URL          string   `json:"url"`
+	Title        string   `json:"title,omitempty"`
+	Snippet      string   `json:"snippet,omitempty"`
+	MatchedTerms []string `json:"matched_terms,omitempty"`
+	Score        float64  `json:"score,omitempty"`
+}
+
+type comicReply struct {
+	ID         int        `json:"id"`
+	URL        string     `json:"url"`
+	Title      string     `json:"title"`
+	Alt        string     `json:"alt"`
+	Transcript string     `json:"transcript"`
+	Published  string     `json:"published,omitempty"`
+	PageURL    string     `json:"page_url"`
+	FetchedAt  *time.Time `json:"fetched_at,omitempty"`
 }
 
 type searchReply struct {
-	Comics []comicsReply `json:"comics"`
-	Total  int           `json:"total"`
+	Comics      []comicsReply `json:"comics"`
+	Total       int           `json:"total"`
+	NextCursor  string        `json:"next_cursor,omitempty"`
+	Suggestions []string      `json:"suggestions,omitempty"`
+}
+
+type completionReply struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+type suggestReply struct {
+	Completions []completionReply `json:"completions"`
+}
+
+type idRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+type refetchRequest struct {
+	IDs          []int     `json:"ids"`
+	Ranges       []idRange `json:"ranges"`
+	Placeholders bool      `json:"placeholders"`
+	EmptyWords   bool      `json:"empty_words"`
 }
 
 type loginRequest struct {
@@ -44,7 +138,10 @@ type authService interface {
 	IssueToken() (string, error)
 }
 
-const DefaultLimit = 10
+const (
+	DefaultLimit = 10
+	maxBodySize  = 1 << 20
+)
 
 func ParseLimit(s string) (int, error) {
 	if s == "" {
@@ -63,6 +160,22 @@ func writeJSON(w http.ResponseWriter, code int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// readJSON decodes a request body of at most maxBodySize bytes into v,
+// answering 413 or 400 itself when it cannot.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+	} else {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}
+	return false
+}
+
 func NewPingHandler(log *slog.Logger, pingers map[string]core.Pinger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		resp := pingReply{Replies: map[string]string{}}
@@ -78,23 +191,199 @@ func NewPingHandler(log *slog.Logger, pingers map[string]core.Pinger) http.Handl
 	}
 }
 
+// NewUpdateHandler starts an update job and answers 202 with it at once;
+// the job is polled at its Location.
 func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := updater.Update(r.Context())
+		job, err := updater.Update(r.Context())
+		writeJobStarted(w, log, "update", job, err)
+	}
+}
+
+// NewReindexHandler starts a job normalizing the stored comics again with
+// the current words service, answering 202 like NewUpdateHandler.
+func NewReindexHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := updater.Reindex(r.Context())
+		writeJobStarted(w, log, "reindex", job, err)
+	}
+}
+
+// writeJobStarted answers 202 with the job started or the one already
+// running.
+func writeJobStarted(w http.ResponseWriter, log *slog.Logger, op string, job core.Job, err error) {
+	status := "started"
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrAlreadyExists):
+			status = "already_running"
+		case errors.Is(err, core.ErrBadArguments):
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		default:
+			log.Error(op+" failed", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/db/jobs/%d", job.ID))
+	writeJSON(w, http.StatusAccepted, updateReply{Status: status, Job: toJobReply(job)})
+}
+
+// NewRefetchHandler starts a job fetching the comics selected by the body
+// again, answering 202 like NewUpdateHandler.
+func NewRefetchHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req refetchRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		refetch := core.Refetch{
+			IDs:          req.IDs,
+			Ranges:       make([]core.IDRange, 0, len(req.Ranges)),
+			Placeholders: req.Placeholders,
+			EmptyWords:   req.EmptyWords,
+		}
+		for _, rng := range req.Ranges {
+			refetch.Ranges = append(refetch.Ranges, core.IDRange{From: rng.From, To: rng.To})
+		}
+
+		job, err := updater.Refetch(r.Context(), refetch)
+		writeJobStarted(w, log, "refetch", job, err)
+	}
+}
+
+func NewCancelUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := updater.Cancel(r.Context())
+		if err != nil {
+			switch {
+			case errors.Is(err, core.ErrNotRunning):
+				http.Error(w, "no update is running", http.StatusConflict)
+			default:
+				log.Error("cancel update failed", "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, toJobReply(job))
+	}
+}
+
+func NewJobHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		job, err := updater.Job(r.Context(), id)
 		if err != nil {
 			switch {
-			case errors.Is(err, core.ErrAlreadyExists):
-				writeJSON(w, http.StatusAccepted, map[string]string{"status": "already_running"})
+			case errors.Is(err, core.ErrNotFound):
+				http.Error(w, "not found", http.StatusNotFound)
 			case errors.Is(err, core.ErrBadArguments):
 				http.Error(w, "bad request", http.StatusBadRequest)
 			default:
-				log.Error("update failed", "error", err)
+				log.Error("get job failed", "id", id, "error", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
 			}
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		writeJSON(w, http.StatusOK, toJobReply(job))
+	}
+}
+
+func NewJobsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := ParseLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		jobs, err := updater.Jobs(r.Context(), limit)
+		if err != nil {
+			switch {
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, "bad request", http.StatusBadRequest)
+			default:
+				log.Error("list jobs failed", "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		out := jobsReply{Jobs: make([]jobReply, 0, len(jobs))}
+		for _, job := range jobs {
+			out.Jobs = append(out.Jobs, toJobReply(job))
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func NewFailuresHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if s := r.URL.Query().Get("limit"); s != "" {
+			var err error
+			if limit, err = ParseLimit(s); err != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		failures, err := updater.Failures(r.Context(), limit)
+		if err != nil {
+			switch {
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, "bad request", http.StatusBadRequest)
+			default:
+				log.Error("list failures failed", "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		out := failuresReply{Failures: make([]failureReply, 0, len(failures))}
+		for _, f := range failures {
+			out.Failures = append(out.Failures, failureReply{
+				ID:            f.ID,
+				Class:         f.Class,
+				Error:         f.Error,
+				Attempts:      f.Attempts,
+				FirstFailedAt: f.FirstFailedAt,
+				LastFailedAt:  f.LastFailedAt,
+				NextRetryAt:   f.NextRetryAt,
+			})
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// toJobReply reports the duration of a running job up to now.
+func toJobReply(job core.Job) jobReply {
+	end := job.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	out := jobReply{
+		ID:           job.ID,
+		Trigger:      job.Trigger,
+		State:        string(job.State),
+		Total:        job.Total,
+		Done:         job.Done,
+		Failed:       job.Failed,
+		Placeholders: job.Placeholders,
+		Errors:       job.Errors,
+		StartedAt:    job.StartedAt,
+		FinishedAt:   timeOrNil(job.FinishedAt),
 	}
+	if !job.StartedAt.IsZero() {
+		out.DurationMS = end.Sub(job.StartedAt).Milliseconds()
+	}
+	return out
 }
 
 func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
@@ -106,10 +395,12 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerF
 			return
 		}
 		writeJSON(w, http.StatusOK, statsReply{
-			WordsTotal:    st.WordsTotal,
-			WordsUnique:   st.WordsUnique,
-			ComicsFetched: st.ComicsFetched,
-			ComicsTotal:   st.ComicsTotal,
+			WordsTotal:     st.WordsTotal,
+			WordsUnique:    st.WordsUnique,
+			ComicsFetched:  st.ComicsFetched,
+			ComicsNotFound: st.ComicsNotFound,
+			ComicsFailed:   st.ComicsFailed,
+			ComicsTotal:    st.ComicsTotal,
 		})
 	}
 }
@@ -122,10 +413,79 @@ func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater) http.Handler
 			http.Error(w, "internal error", http.StatusInternalServerError)
 			return
 		}
-		writeJSON(w, http.StatusOK, statusReply{Status: string(st)})
+		writeJSON(w, http.StatusOK, toStatusReply(st))
+	}
+}
+
+// NewUpdateStatusStreamHandler pushes the update status as server-sent
+// events on every change until the client goes away or shutdown is done.
+func NewUpdateStatusStreamHandler(
+	log *slog.Logger, updater core.Updater, shutdown context.Context,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		defer context.AfterFunc(shutdown, cancel)()
+
+		states, err := updater.WatchStatus(ctx)
+		if err != nil {
+			log.Error("watch status failed", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for st := range states {
+			data, err := json.Marshal(toStatusReply(st))
+			if err != nil {
+				log.Error("cannot encode status", "error", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
 	}
 }
 
+func toStatusReply(st core.UpdateState) statusReply {
+	reply := statusReply{Status: string(st.Status)}
+	if !st.NextRun.IsZero() {
+		reply.NextRun = &st.NextRun
+	}
+	if p := st.Progress; !p.StartedAt.IsZero() {
+		reply.Progress = &progressReply{
+			JobID:        p.JobID,
+			Total:        p.Total,
+			Done:         p.Done,
+			Failed:       p.Failed,
+			Placeholders: p.Placeholders,
+			StartedAt:    p.StartedAt,
+			FinishedAt:   timeOrNil(p.FinishedAt),
+			ETA:          timeOrNil(p.ETA),
+		}
+	}
+	return reply
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := updater.Drop(r.Context()); err != nil {
@@ -138,25 +498,30 @@ func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 }
 
 func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		phrase := r.URL.Query().Get("phrase")
-		limitStr := r.URL.Query().Get("limit")
+	return newSearchHandler(log, searcher.Search)
+}
+
+func NewISearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return newSearchHandler(log, searcher.ISearch)
+}
 
-		limit, err := ParseLimit(limitStr)
+func newSearchHandler(
+	log *slog.Logger, search func(context.Context, core.SearchParams) (core.SearchResult, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := parseSearchParams(r)
 		if err != nil {
 			http.Error(w, "bad request", http.StatusBadRequest)
 			return
 		}
-		if phrase == "" {
-			http.Error(w, "bad request", http.StatusBadRequest)
-			return
-		}
 
-		res, err := searcher.Search(r.Context(), phrase, limit)
+		res, err := search(r.Context(), params)
 		if err != nil {
 			switch {
 			case errors.Is(err, core.ErrBadPhrase), errors.Is(err, core.ErrBadLimit):
 				http.Error(w, "bad request", http.StatusBadRequest)
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, err.Error(), http.StatusBadRequest)
 			default:
 				log.Error("search failed", "error", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
@@ -165,13 +530,19 @@ func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc
 		}
 
 		out := searchReply{
-			Comics: make([]comicsReply, 0, len(res.Comics)),
-			Total:  res.Total,
+			Comics:      make([]comicsReply, 0, len(res.Comics)),
+			Total:       res.Total,
+			NextCursor:  res.NextCursor,
+			Suggestions: res.Suggestions,
 		}
 		for _, c := range res.Comics {
 			out.Comics = append(out.Comics, comicsReply{
-				ID:  c.ID,
-				URL: c.URL,
+				ID:           c.ID,
+				URL:          c.URL,
+				Title:        c.Title,
+				Snippet:      c.Snippet,
+				MatchedTerms: c.MatchedTerms,
+				Score:        c.Score,
 			})
 		}
 
@@ -179,44 +550,110 @@ func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc
 	}
 }
 
-func NewISearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+// parseSearchParams reads phrase, limit, the fuzzy flag and the page, given
+// either as a 1-based page number or as the cursor of the previous reply.
+func parseSearchParams(r *http.Request) (core.SearchParams, error) {
+	query := r.URL.Query()
+	params := core.SearchParams{
+		Phrase: query.Get("phrase"),
+		Cursor: query.Get("cursor"),
+	}
+	if params.Phrase == "" {
+		return core.SearchParams{}, core.ErrBadPhrase
+	}
+	limit, err := ParseLimit(query.Get("limit"))
+	if err != nil {
+		return core.SearchParams{}, err
+	}
+	params.Limit = limit
+
+	if s := query.Get("page"); s != "" {
+		page, err := strconv.Atoi(s)
+		if err != nil || page <= 0 || page-1 > math.MaxInt32/limit {
+			return core.SearchParams{}, core.ErrBadArguments
+		}
+		params.Offset = (page - 1) * limit
+	}
+	if s := query.Get("fuzzy"); s != "" {
+		fuzzy, err := strconv.ParseBool(s)
+		if err != nil {
+			return core.SearchParams{}, core.ErrBadArguments
+		}
+		params.Fuzzy = fuzzy
+	}
+	return params, nil
+}
+
+func NewComicHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		phrase := r.URL.Query().Get("phrase")
-		limitStr := r.URL.Query().Get("limit")
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
 
-		limit, err := ParseLimit(limitStr)
+		c, err := searcher.GetComic(r.Context(), id)
 		if err != nil {
+			switch {
+			case errors.Is(err, core.ErrNotFound):
+				http.Error(w, "not found", http.StatusNotFound)
+			case errors.Is(err, core.ErrBadArguments):
+				http.Error(w, "bad request", http.StatusBadRequest)
+			default:
+				log.Error("get comic failed", "id", id, "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		out := comicReply{
+			ID:         c.ID,
+			URL:        c.URL,
+			Title:      c.Title,
+			Alt:        c.Alt,
+			Transcript: c.Transcript,
+			PageURL:    c.PageURL,
+		}
+		if !c.Published.IsZero() {
+			out.Published = c.Published.Format(time.DateOnly)
+		}
+		if !c.FetchedAt.IsZero() {
+			out.FetchedAt = &c.FetchedAt
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func NewSuggestHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		prefix := query.Get("prefix")
+		if prefix == "" {
 			http.Error(w, "bad request", http.StatusBadRequest)
 			return
 		}
-		if phrase == "" {
+		limit, err := ParseLimit(query.Get("limit"))
+		if err != nil {
 			http.Error(w, "bad request", http.StatusBadRequest)
 			return
 		}
 
-		res, err := searcher.ISearch(r.Context(), phrase, limit)
+		completions, err := searcher.Suggest(r.Context(), prefix, limit)
 		if err != nil {
 			switch {
-			case errors.Is(err, core.ErrBadPhrase), errors.Is(err, core.ErrBadLimit):
+			case errors.Is(err, core.ErrBadArguments), errors.Is(err, core.ErrBadLimit):
 				http.Error(w, "bad request", http.StatusBadRequest)
 			default:
-				log.Error("search failed", "error", err)
+				log.Error("suggest failed", "prefix", prefix, "error", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
 			}
 			return
 		}
 
-		out := searchReply{
-			Comics: make([]comicsReply, 0, len(res.Comics)),
-			Total:  res.Total,
+		out := suggestReply{Completions: make([]completionReply, 0, len(completions))}
+		for _, c := range completions {
+			out.Completions = append(out.Completions, completionReply{Term: c.Term, Count: c.Count})
 		}
-		for _, c := range res.Comics {
-			out.Comics = append(out.Comics, comicsReply{
-				ID:  c.ID,
-				URL: c.URL,
-			})
-		}
-
 		writeJSON(w, http.StatusOK, out)
 	}
 }
@@ -224,8 +661,7 @@ func NewISearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFun
 func NewLoginHandler(log *slog.Logger, auth authService, adminUser, adminPass string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req loginRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "bad request", http.StatusBadRequest)
+		if !readJSON(w, r, &req) {
 			return
 		}
 
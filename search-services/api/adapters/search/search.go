@@ -2,10 +2,14 @@ package search
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"yadro.com/course/api/core"
 	searchpb "yadro.com/course/proto/search"
@@ -32,62 +36,131 @@ func (c Client) Ping(ctx context.Context) error {
 	return err
 }
 
-func (c Client) Search(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	if phrase == "" {
-		return core.SearchResult{}, core.ErrBadPhrase
+func (c Client) Search(ctx context.Context, params core.SearchParams) (core.SearchResult, error) {
+	req, err := toRequest(params)
+	if err != nil {
+		return core.SearchResult{}, err
 	}
-	if limit <= 0 {
-		return core.SearchResult{}, core.ErrBadLimit
+	resp, err := c.client.Search(ctx, req)
+	if err != nil {
+		c.log.Warn("search rpc failed", "error", err)
+		return core.SearchResult{}, mapErr(err)
 	}
+	return toResult(resp), nil
+}
 
-	resp, err := c.client.Search(ctx, &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  uint32(limit),
-	})
+func (c Client) ISearch(ctx context.Context, params core.SearchParams) (core.SearchResult, error) {
+	req, err := toRequest(params)
 	if err != nil {
-		c.log.Warn("search rpc failed", "error", err)
 		return core.SearchResult{}, err
 	}
+	resp, err := c.client.ISearch(ctx, req)
+	if err != nil {
+		c.log.Warn("search rpc failed", "error", err)
+		return core.SearchResult{}, mapErr(err)
+	}
+	return toResult(resp), nil
+}
 
+func toRequest(params core.SearchParams) (*searchpb.SearchRequest, error) {
+	if params.Phrase == "" {
+		return nil, core.ErrBadPhrase
+	}
+	if params.Limit <= 0 || int64(params.Limit) > math.MaxUint32 {
+		return nil, core.ErrBadLimit
+	}
+	if params.Offset < 0 || int64(params.Offset) > math.MaxUint32 {
+		return nil, core.ErrBadArguments
+	}
+	return &searchpb.SearchRequest{
+		Phrase: params.Phrase,
+		Limit:  uint32(params.Limit),
+		Offset: uint32(params.Offset),
+		Cursor: params.Cursor,
+		Fuzzy:  params.Fuzzy,
+	}, nil
+}
+
+func toResult(resp *searchpb.SearchReply) core.SearchResult {
 	out := core.SearchResult{
-		Comics: make([]core.Comics, 0, len(resp.GetComics())),
-		Total:  int(resp.GetTotal()),
+		Comics:      make([]core.Comics, 0, len(resp.GetComics())),
+		Total:       int(resp.GetTotal()),
+		NextCursor:  resp.GetNextCursor(),
+		Suggestions: resp.GetSuggestions(),
 	}
 	for _, cpb := range resp.GetComics() {
 		out.Comics = append(out.Comics, core.Comics{
-			ID:  int(cpb.GetId()),
-			URL: cpb.GetUrl(),
+			ID:           int(cpb.GetId()),
+			URL:          cpb.GetUrl(),
+			Title:        cpb.GetTitle(),
+			Snippet:      cpb.GetSnippet(),
+			MatchedTerms: cpb.GetMatchedTerms(),
+			Score:        cpb.GetScore(),
 		})
 	}
+	return out
+}
+
+func (c Client) GetComic(ctx context.Context, id int) (core.Comic, error) {
+	if id <= 0 {
+		return core.Comic{}, core.ErrBadArguments
+	}
+
+	resp, err := c.client.GetComic(ctx, &searchpb.GetComicRequest{Id: int32(id)})
+	if err != nil {
+		c.log.Warn("get comic rpc failed", "id", id, "error", err)
+		return core.Comic{}, mapErr(err)
+	}
+
+	out := core.Comic{
+		ID:         int(resp.GetId()),
+		URL:        resp.GetUrl(),
+		Title:      resp.GetTitle(),
+		Alt:        resp.GetAlt(),
+		Transcript: resp.GetTranscript(),
+		PageURL:    resp.GetPageUrl(),
+	}
+	if resp.GetPublished() != nil {
+		out.Published = resp.GetPublished().AsTime()
+	}
+	if resp.GetFetchedAt() != nil {
+		out.FetchedAt = resp.GetFetchedAt().AsTime()
+	}
 	return out, nil
 }
 
-func (c Client) ISearch(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	if phrase == "" {
-		return core.SearchResult{}, core.ErrBadPhrase
+func (c Client) Suggest(ctx context.Context, prefix string, limit int) ([]core.Completion, error) {
+	if prefix == "" {
+		return nil, core.ErrBadArguments
 	}
 	if limit <= 0 {
-		return core.SearchResult{}, core.ErrBadLimit
+		return nil, core.ErrBadLimit
 	}
 
-	resp, err := c.client.ISearch(ctx, &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  uint32(limit),
-	})
+	resp, err := c.client.Suggest(ctx, &searchpb.SuggestRequest{Prefix: prefix, Limit: uint32(limit)})
 	if err != nil {
-		c.log.Warn("search rpc failed", "error", err)
-		return core.SearchResult{}, err
+		c.log.Warn("suggest rpc failed", "prefix", prefix, "error", err)
+		return nil, mapErr(err)
 	}
 
-	out := core.SearchResult{
-		Comics: make([]core.Comics, 0, len(resp.GetComics())),
-		Total:  int(resp.GetTotal()),
-	}
-	for _, cpb := range resp.GetComics() {
-		out.Comics = append(out.Comics, core.Comics{
-			ID:  int(cpb.GetId()),
-			URL: cpb.GetUrl(),
-		})
+	out := make([]core.Completion, 0, len(resp.GetCompletions()))
+	for _, cpb := range resp.GetCompletions() {
+		out = append(out, core.Completion{Term: cpb.GetTerm(), Count: int(cpb.GetCount())})
 	}
 	return out, nil
 }
+
+func mapErr(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", core.ErrBadArguments, s.Message())
+	case codes.NotFound:
+		return core.ErrNotFound
+	default:
+		return err
+	}
+}
@@ -2,14 +2,18 @@ package update
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"yadro.com/course/api/core"
 	updatepb "yadro.com/course/proto/update"
@@ -36,19 +40,73 @@ func (c Client) Ping(ctx context.Context) error {
 	return mapErr(err)
 }
 
-func (c Client) Status(ctx context.Context) (core.UpdateStatus, error) {
+func (c Client) Status(ctx context.Context) (core.UpdateState, error) {
 	resp, err := c.client.Status(ctx, &emptypb.Empty{})
 	if err != nil {
-		return core.StatusUpdateUnknown, mapErr(err)
+		return core.UpdateState{Status: core.StatusUpdateUnknown}, mapErr(err)
 	}
+	return toState(resp), nil
+}
+
+// WatchStatus streams status changes of the update service until ctx is
+// done or the stream breaks, then closes the channel.
+func (c Client) WatchStatus(ctx context.Context) (<-chan core.UpdateState, error) {
+	stream, err := c.client.WatchStatus(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	ch := make(chan core.UpdateState)
+	go func() {
+		defer close(ch)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					c.log.Warn("status stream broken", "error", err)
+				}
+				return
+			}
+			select {
+			case ch <- toState(resp):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func toState(resp *updatepb.StatusReply) core.UpdateState {
+	state := core.UpdateState{Status: core.StatusUpdateUnknown}
 	switch resp.GetStatus() {
 	case updatepb.Status_STATUS_IDLE:
-		return core.StatusUpdateIdle, nil
+		state.Status = core.StatusUpdateIdle
 	case updatepb.Status_STATUS_RUNNING:
-		return core.StatusUpdateRunning, nil
-	default:
-		return core.StatusUpdateUnknown, nil
+		state.Status = core.StatusUpdateRunning
 	}
+	if resp.NextRun != nil {
+		state.NextRun = resp.GetNextRun().AsTime()
+	}
+	if p := resp.GetProgress(); p != nil {
+		state.Progress = core.UpdateProgress{
+			JobID:        p.GetJobId(),
+			Total:        int(p.GetTotal()),
+			Done:         int(p.GetDone()),
+			Failed:       int(p.GetFailed()),
+			Placeholders: int(p.GetPlaceholders()),
+			StartedAt:    asTime(p.GetStartedAt()),
+			FinishedAt:   asTime(p.GetFinishedAt()),
+			ETA:          asTime(p.GetEta()),
+		}
+	}
+	return state
+}
+
+func asTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
 }
 
 func (c Client) Stats(ctx context.Context) (core.UpdateStats, error) {
@@ -57,16 +115,137 @@ func (c Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 		return core.UpdateStats{}, mapErr(err)
 	}
 	return core.UpdateStats{
-		WordsTotal:    int(resp.GetWordsTotal()),
-		WordsUnique:   int(resp.GetWordsUnique()),
-		ComicsFetched: int(resp.GetComicsFetched()),
-		ComicsTotal:   int(resp.GetComicsTotal()),
+		WordsTotal:     int(resp.GetWordsTotal()),
+		WordsUnique:    int(resp.GetWordsUnique()),
+		ComicsFetched:  int(resp.GetComicsFetched()),
+		ComicsNotFound: int(resp.GetComicsNotFound()),
+		ComicsFailed:   int(resp.GetComicsFailed()),
+		ComicsTotal:    int(resp.GetComicsTotal()),
 	}, nil
 }
 
-func (c Client) Update(ctx context.Context) error {
-	_, err := c.client.Update(ctx, &emptypb.Empty{})
-	return mapErr(err)
+// Update starts an update job; a job already running is returned with
+// core.ErrAlreadyExists.
+func (c Client) Update(ctx context.Context) (core.Job, error) {
+	resp, err := c.client.Update(ctx, &emptypb.Empty{})
+	if err != nil {
+		return core.Job{}, mapErr(err)
+	}
+	if resp.GetAlreadyRunning() {
+		return toJob(resp.GetJob()), core.ErrAlreadyExists
+	}
+	return toJob(resp.GetJob()), nil
+}
+
+// Reindex starts a job normalizing the stored comics again; a job already
+// running is returned with core.ErrAlreadyExists.
+func (c Client) Reindex(ctx context.Context) (core.Job, error) {
+	resp, err := c.client.Reindex(ctx, &emptypb.Empty{})
+	if err != nil {
+		return core.Job{}, mapErr(err)
+	}
+	if resp.GetAlreadyRunning() {
+		return toJob(resp.GetJob()), core.ErrAlreadyExists
+	}
+	return toJob(resp.GetJob()), nil
+}
+
+// Refetch starts a job fetching the selected comics again; a job already
+// running is returned with core.ErrAlreadyExists.
+func (c Client) Refetch(ctx context.Context, r core.Refetch) (core.Job, error) {
+	req := &updatepb.RefetchRequest{
+		Ids:          make([]int64, 0, len(r.IDs)),
+		Ranges:       make([]*updatepb.IDRange, 0, len(r.Ranges)),
+		Placeholders: r.Placeholders,
+		EmptyWords:   r.EmptyWords,
+	}
+	for _, id := range r.IDs {
+		req.Ids = append(req.Ids, int64(id))
+	}
+	for _, rng := range r.Ranges {
+		req.Ranges = append(req.Ranges, &updatepb.IDRange{From: int64(rng.From), To: int64(rng.To)})
+	}
+	resp, err := c.client.Refetch(ctx, req)
+	if err != nil {
+		return core.Job{}, mapErr(err)
+	}
+	if resp.GetAlreadyRunning() {
+		return toJob(resp.GetJob()), core.ErrAlreadyExists
+	}
+	return toJob(resp.GetJob()), nil
+}
+
+func (c Client) Cancel(ctx context.Context) (core.Job, error) {
+	resp, err := c.client.Cancel(ctx, &emptypb.Empty{})
+	if err != nil {
+		return core.Job{}, mapErr(err)
+	}
+	return toJob(resp), nil
+}
+
+func (c Client) Job(ctx context.Context, id int64) (core.Job, error) {
+	resp, err := c.client.GetJob(ctx, &updatepb.GetJobRequest{Id: id})
+	if err != nil {
+		return core.Job{}, mapErr(err)
+	}
+	return toJob(resp), nil
+}
+
+func (c Client) Jobs(ctx context.Context, limit int) ([]core.Job, error) {
+	resp, err := c.client.ListJobs(ctx, &updatepb.ListJobsRequest{Limit: int32(limit)})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	jobs := make([]core.Job, 0, len(resp.GetJobs()))
+	for _, j := range resp.GetJobs() {
+		jobs = append(jobs, toJob(j))
+	}
+	return jobs, nil
+}
+
+func (c Client) Failures(ctx context.Context, limit int) ([]core.Failure, error) {
+	resp, err := c.client.ListFailures(ctx, &updatepb.ListFailuresRequest{Limit: int32(limit)})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	failures := make([]core.Failure, 0, len(resp.GetFailures()))
+	for _, f := range resp.GetFailures() {
+		failures = append(failures, core.Failure{
+			ID:            int(f.GetId()),
+			Class:         f.GetClass(),
+			Error:         f.GetError(),
+			Attempts:      int(f.GetAttempts()),
+			FirstFailedAt: asTime(f.GetFirstFailedAt()),
+			LastFailedAt:  asTime(f.GetLastFailedAt()),
+			NextRetryAt:   asTime(f.GetNextRetryAt()),
+		})
+	}
+	return failures, nil
+}
+
+func toJob(j *updatepb.Job) core.Job {
+	job := core.Job{
+		ID:           j.GetId(),
+		Trigger:      j.GetTrigger(),
+		Total:        int(j.GetTotal()),
+		Done:         int(j.GetDone()),
+		Failed:       int(j.GetFailed()),
+		Placeholders: int(j.GetPlaceholders()),
+		Errors:       j.GetErrors(),
+		StartedAt:    asTime(j.GetStartedAt()),
+		FinishedAt:   asTime(j.GetFinishedAt()),
+	}
+	switch j.GetState() {
+	case updatepb.JobState_JOB_STATE_RUNNING:
+		job.State = core.JobRunning
+	case updatepb.JobState_JOB_STATE_SUCCEEDED:
+		job.State = core.JobSucceeded
+	case updatepb.JobState_JOB_STATE_FAILED:
+		job.State = core.JobFailed
+	case updatepb.JobState_JOB_STATE_CANCELLED:
+		job.State = core.JobCancelled
+	}
+	return job
 }
 
 func (c Client) Drop(ctx context.Context) error {
@@ -87,6 +266,10 @@ func mapErr(err error) error {
 		return core.ErrBadArguments
 	case codes.AlreadyExists:
 		return core.ErrAlreadyExists
+	case codes.NotFound:
+		return core.ErrNotFound
+	case codes.FailedPrecondition:
+		return core.ErrNotRunning
 	default:
 		return err
 	}
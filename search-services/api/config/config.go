@@ -25,6 +25,7 @@ type Config struct {
 
 	SearchConcurrency int `yaml:"search_concurrency" env:"SEARCH_CONCURRENCY" env-default:"10"`
 	SearchRate        int `yaml:"search_rate"        env:"SEARCH_RATE"        env-default:"100"`
+	SuggestRate       int `yaml:"suggest_rate"       env:"SUGGEST_RATE"       env-default:"100"`
 }
 
 func MustLoad(configPath string) Config {
@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type UpdateStatus string
 
 const (
@@ -8,19 +10,118 @@ const (
 	StatusUpdateRunning UpdateStatus = "running"
 )
 
+// UpdateProgress is the progress of the running or the last finished update
+// job; StartedAt is zero before the first one.
+type UpdateProgress struct {
+	JobID        int64
+	Total        int
+	Done         int
+	Failed       int
+	Placeholders int
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	ETA          time.Time
+}
+
+type UpdateState struct {
+	Status   UpdateStatus
+	NextRun  time.Time
+	Progress UpdateProgress
+}
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Job is an update run of the update service.
+type Job struct {
+	ID           int64
+	Trigger      string
+	State        JobState
+	Total        int
+	Done         int
+	Failed       int
+	Placeholders int
+	Errors       []string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+type IDRange struct {
+	From int
+	To   int
+}
+
+// Refetch selects stored comics to fetch from xkcd again.
+type Refetch struct {
+	IDs          []int
+	Ranges       []IDRange
+	Placeholders bool
+	EmptyWords   bool
+}
+
+// Failure is a comic the update service failed to fetch or store and
+// retries later.
+type Failure struct {
+	ID            int
+	Class         string
+	Error         string
+	Attempts      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+	NextRetryAt   time.Time
+}
+
 type UpdateStats struct {
-	WordsTotal    int
-	WordsUnique   int
-	ComicsFetched int
-	ComicsTotal   int
+	WordsTotal     int
+	WordsUnique    int
+	ComicsFetched  int
+	ComicsNotFound int
+	ComicsFailed   int
+	ComicsTotal    int
 }
 
 type Comics struct {
-	ID  int
-	URL string
+	ID           int
+	URL          string
+	Title        string
+	Snippet      string
+	MatchedTerms []string
+	Score        float64
+}
+
+type Comic struct {
+	ID         int
+	URL        string
+	Title      string
+	Alt        string
+	Transcript string
+	Published  time.Time
+	PageURL    string
+	FetchedAt  time.Time
+}
+
+type SearchParams struct {
+	Phrase string
+	Limit  int
+	Offset int
+	Cursor string
+	Fuzzy  bool
 }
 
 type SearchResult struct {
-	Comics []Comics
-	Total  int
+	Comics      []Comics
+	Total       int
+	NextCursor  string
+	Suggestions []string
+}
+
+type Completion struct {
+	Term  string
+	Count int
 }
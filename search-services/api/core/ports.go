@@ -11,13 +11,22 @@ type Pinger interface {
 }
 
 type Updater interface {
-	Update(context.Context) error
+	Update(context.Context) (Job, error)
+	Cancel(context.Context) (Job, error)
+	Refetch(context.Context, Refetch) (Job, error)
+	Reindex(context.Context) (Job, error)
+	Job(context.Context, int64) (Job, error)
+	Jobs(context.Context, int) ([]Job, error)
+	Failures(context.Context, int) ([]Failure, error)
 	Stats(context.Context) (UpdateStats, error)
-	Status(context.Context) (UpdateStatus, error)
+	Status(context.Context) (UpdateState, error)
+	WatchStatus(context.Context) (<-chan UpdateState, error)
 	Drop(context.Context) error
 }
 
 type Searcher interface {
-	Search(context.Context, string, int) (SearchResult, error)
-	ISearch(context.Context, string, int) (SearchResult, error)
+	Search(context.Context, SearchParams) (SearchResult, error)
+	ISearch(context.Context, SearchParams) (SearchResult, error)
+	GetComic(context.Context, int) (Comic, error)
+	Suggest(context.Context, string, int) ([]Completion, error)
 }
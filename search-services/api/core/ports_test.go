@@ -26,19 +26,55 @@ func (p *testPinger) Ping(ctx context.Context) error {
 
 type testUpdater struct{}
 
-func (testUpdater) Update(ctx context.Context) error                       { return nil }
-func (testUpdater) Stats(ctx context.Context) (core.UpdateStats, error)   { return core.UpdateStats{}, nil }
-func (testUpdater) Status(ctx context.Context) (core.UpdateStatus, error) { return core.StatusUpdateIdle, nil }
-func (testUpdater) Drop(ctx context.Context) error                        { return nil }
+func (testUpdater) Update(ctx context.Context) (core.Job, error) {
+	return core.Job{ID: 1, State: core.JobRunning}, nil
+}
+func (testUpdater) Cancel(ctx context.Context) (core.Job, error) {
+	return core.Job{ID: 1, State: core.JobCancelled}, nil
+}
+func (testUpdater) Refetch(ctx context.Context, r core.Refetch) (core.Job, error) {
+	return core.Job{ID: 1, Trigger: "refetch", State: core.JobRunning}, nil
+}
+func (testUpdater) Reindex(ctx context.Context) (core.Job, error) {
+	return core.Job{ID: 1, Trigger: "reindex", State: core.JobRunning}, nil
+}
+func (testUpdater) Job(ctx context.Context, id int64) (core.Job, error) {
+	return core.Job{ID: id, State: core.JobSucceeded}, nil
+}
+func (testUpdater) Jobs(ctx context.Context, limit int) ([]core.Job, error) { return nil, nil }
+func (testUpdater) Failures(ctx context.Context, limit int) ([]core.Failure, error) {
+	return nil, nil
+}
+func (testUpdater) Stats(ctx context.Context) (core.UpdateStats, error) {
+	return core.UpdateStats{}, nil
+}
+func (testUpdater) Status(ctx context.Context) (core.UpdateState, error) {
+	return core.UpdateState{Status: core.StatusUpdateIdle}, nil
+}
+func (testUpdater) WatchStatus(ctx context.Context) (<-chan core.UpdateState, error) {
+	ch := make(chan core.UpdateState, 1)
+	ch <- core.UpdateState{Status: core.StatusUpdateIdle}
+	close(ch)
+	return ch, nil
+}
+func (testUpdater) Drop(ctx context.Context) error { return nil }
 
 type testSearcher struct{}
 
-func (testSearcher) Search(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	return core.SearchResult{Total: limit}, nil
+func (testSearcher) Search(ctx context.Context, params core.SearchParams) (core.SearchResult, error) {
+	return core.SearchResult{Total: params.Limit}, nil
+}
+
+func (testSearcher) ISearch(ctx context.Context, params core.SearchParams) (core.SearchResult, error) {
+	return core.SearchResult{Total: params.Limit}, nil
+}
+
+func (testSearcher) GetComic(ctx context.Context, id int) (core.Comic, error) {
+	return core.Comic{ID: id}, nil
 }
 
-func (testSearcher) ISearch(ctx context.Context, phrase string, limit int) (core.SearchResult, error) {
-	return core.SearchResult{Total: limit}, nil
+func (testSearcher) Suggest(ctx context.Context, prefix string, limit int) ([]core.Completion, error) {
+	return []core.Completion{{Term: prefix, Count: limit}}, nil
 }
 
 func TestInterfacesImplemented(t *testing.T) {
@@ -60,11 +96,11 @@ func TestTestPingerCalled(t *testing.T) {
 func TestTestSearcher(t *testing.T) {
 	ctx := context.Background()
 	s := testSearcher{}
-	res, err := s.Search(ctx, "linux", 5)
+	res, err := s.Search(ctx, core.SearchParams{Phrase: "linux", Limit: 5})
 	require.NoError(t, err)
 	require.Equal(t, 5, res.Total)
 
-	ires, err := s.ISearch(ctx, "linux", 3)
+	ires, err := s.ISearch(ctx, core.SearchParams{Phrase: "linux", Limit: 3})
 	require.NoError(t, err)
 	require.Equal(t, 3, ires.Total)
 }
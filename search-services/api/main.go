@@ -62,18 +62,33 @@ func main() {
 	authMw := middleware.AuthMiddleware(authSvc)
 	concurrencyLimiter := middleware.NewConcurrencyLimiter(cfg.SearchConcurrency)
 	rateLimiter := middleware.NewRateLimiter(cfg.SearchRate)
+	suggestLimiter := middleware.NewRateLimiter(cfg.SuggestRate)
+
+	// status streams never end on their own, so they are closed before
+	// shutdown waits for active requests
+	streams, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
 
 	mux := http.NewServeMux()
 
 	mux.Handle("GET /api/ping", rest.NewPingHandler(log, pingers))
 	mux.Handle("GET /api/db/stats", rest.NewUpdateStatsHandler(log, updateClient))
 	mux.Handle("GET /api/db/status", rest.NewUpdateStatusHandler(log, updateClient))
+	mux.Handle("GET /api/db/status/stream", rest.NewUpdateStatusStreamHandler(log, updateClient, streams))
+	mux.Handle("GET /api/db/jobs", rest.NewJobsHandler(log, updateClient))
+	mux.Handle("GET /api/db/jobs/{id}", rest.NewJobHandler(log, updateClient))
+	mux.Handle("GET /api/db/failures", rest.NewFailuresHandler(log, updateClient))
 	mux.Handle("POST /api/login", rest.NewLoginHandler(log, authSvc, cfg.AdminUser, cfg.AdminPass))
 
 	mux.Handle("GET /api/search", concurrencyLimiter.Wrap(rest.NewSearchHandler(log, searchClient)))
 	mux.Handle("GET /api/isearch", rateLimiter.Wrap(rest.NewISearchHandler(log, searchClient)))
+	mux.Handle("GET /api/suggest", suggestLimiter.Wrap(rest.NewSuggestHandler(log, searchClient)))
+	mux.Handle("GET /api/comics/{id}", rest.NewComicHandler(log, searchClient))
 
 	mux.Handle("POST /api/db/update", authMw(rest.NewUpdateHandler(log, updateClient)))
+	mux.Handle("POST /api/db/refetch", authMw(rest.NewRefetchHandler(log, updateClient)))
+	mux.Handle("POST /api/db/reindex", authMw(rest.NewReindexHandler(log, updateClient)))
+	mux.Handle("POST /api/db/update/cancel", authMw(rest.NewCancelUpdateHandler(log, updateClient)))
 	mux.Handle("DELETE /api/db", authMw(rest.NewDropHandler(log, updateClient)))
 
 	server := http.Server{
@@ -81,6 +96,7 @@ func main() {
 		ReadTimeout: cfg.HTTPConfig.Timeout,
 		Handler:     mux,
 	}
+	server.RegisterOnShutdown(closeStreams)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
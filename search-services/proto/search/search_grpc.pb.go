@@ -20,9 +20,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Search_Ping_FullMethodName    = "/search.Search/Ping"
-	Search_Search_FullMethodName  = "/search.Search/Search"
-	Search_ISearch_FullMethodName = "/search.Search/ISearch"
+	Search_Ping_FullMethodName     = "/search.Search/Ping"
+	Search_Search_FullMethodName   = "/search.Search/Search"
+	Search_ISearch_FullMethodName  = "/search.Search/ISearch"
+	Search_GetComic_FullMethodName = "/search.Search/GetComic"
+	Search_Suggest_FullMethodName  = "/search.Search/Suggest"
 )
 
 // SearchClient is the client API for Search service.
@@ -32,6 +34,8 @@ type SearchClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
 	ISearch(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
+	GetComic(ctx context.Context, in *GetComicRequest, opts ...grpc.CallOption) (*ComicInfo, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestReply, error)
 }
 
 type searchClient struct {
@@ -72,6 +76,26 @@ func (c *searchClient) ISearch(ctx context.Context, in *SearchRequest, opts ...g
 	return out, nil
 }
 
+func (c *searchClient) GetComic(ctx context.Context, in *GetComicRequest, opts ...grpc.CallOption) (*ComicInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComicInfo)
+	err := c.cc.Invoke(ctx, Search_GetComic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuggestReply)
+	err := c.cc.Invoke(ctx, Search_Suggest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServer is the server API for Search service.
 // All implementations must embed UnimplementedSearchServer
 // for forward compatibility.
@@ -79,6 +103,8 @@ type SearchServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Search(context.Context, *SearchRequest) (*SearchReply, error)
 	ISearch(context.Context, *SearchRequest) (*SearchReply, error)
+	GetComic(context.Context, *GetComicRequest) (*ComicInfo, error)
+	Suggest(context.Context, *SuggestRequest) (*SuggestReply, error)
 	mustEmbedUnimplementedSearchServer()
 }
 
@@ -98,6 +124,12 @@ func (UnimplementedSearchServer) Search(context.Context, *SearchRequest) (*Searc
 func (UnimplementedSearchServer) ISearch(context.Context, *SearchRequest) (*SearchReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ISearch not implemented")
 }
+func (UnimplementedSearchServer) GetComic(context.Context, *GetComicRequest) (*ComicInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetComic not implemented")
+}
+func (UnimplementedSearchServer) Suggest(context.Context, *SuggestRequest) (*SuggestReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
 func (UnimplementedSearchServer) mustEmbedUnimplementedSearchServer() {}
 func (UnimplementedSearchServer) testEmbeddedByValue()                {}
 
@@ -173,6 +205,42 @@ func _Search_ISearch_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Search_GetComic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetComicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).GetComic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_GetComic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).GetComic(ctx, req.(*GetComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Suggest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Search_ServiceDesc is the grpc.ServiceDesc for Search service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -192,6 +260,14 @@ var Search_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ISearch",
 			Handler:    _Search_ISearch_Handler,
 		},
+		{
+			MethodName: "GetComic",
+			Handler:    _Search_GetComic_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _Search_Suggest_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "search/search.proto",
@@ -20,11 +20,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Update_Ping_FullMethodName   = "/update.Update/Ping"
-	Update_Status_FullMethodName = "/update.Update/Status"
-	Update_Update_FullMethodName = "/update.Update/Update"
-	Update_Stats_FullMethodName  = "/update.Update/Stats"
-	Update_Drop_FullMethodName   = "/update.Update/Drop"
+	Update_Ping_FullMethodName         = "/update.Update/Ping"
+	Update_Status_FullMethodName       = "/update.Update/Status"
+	Update_WatchStatus_FullMethodName  = "/update.Update/WatchStatus"
+	Update_Update_FullMethodName       = "/update.Update/Update"
+	Update_Refetch_FullMethodName      = "/update.Update/Refetch"
+	Update_Reindex_FullMethodName      = "/update.Update/Reindex"
+	Update_Cancel_FullMethodName       = "/update.Update/Cancel"
+	Update_GetJob_FullMethodName       = "/update.Update/GetJob"
+	Update_ListJobs_FullMethodName     = "/update.Update/ListJobs"
+	Update_ListFailures_FullMethodName = "/update.Update/ListFailures"
+	Update_Stats_FullMethodName        = "/update.Update/Stats"
+	Update_Drop_FullMethodName         = "/update.Update/Drop"
 )
 
 // UpdateClient is the client API for Update service.
@@ -33,7 +40,19 @@ const (
 type UpdateClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusReply, error)
-	Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	WatchStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusReply], error)
+	// Update starts an update job and returns it without waiting for the end.
+	Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error)
+	// Refetch starts a job fetching stored comics again and replacing them.
+	Refetch(ctx context.Context, in *RefetchRequest, opts ...grpc.CallOption) (*UpdateReply, error)
+	// Reindex starts a job normalizing stored comics again with the current
+	// version of the words service, resuming one cut short.
+	Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error)
+	// Cancel stops the running job and returns it once it has stopped.
+	Cancel(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Job, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsReply, error)
+	ListFailures(ctx context.Context, in *ListFailuresRequest, opts ...grpc.CallOption) (*ListFailuresReply, error)
 	Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error)
 	Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
@@ -66,9 +85,28 @@ func (c *updateClient) Status(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
-func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *updateClient) WatchStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusReply], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	stream, err := c.cc.NewStream(ctx, &Update_ServiceDesc.Streams[0], Update_WatchStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, StatusReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchStatusClient = grpc.ServerStreamingClient[StatusReply]
+
+func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateReply)
 	err := c.cc.Invoke(ctx, Update_Update_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -76,6 +114,66 @@ func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
+func (c *updateClient) Refetch(ctx context.Context, in *RefetchRequest, opts ...grpc.CallOption) (*UpdateReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateReply)
+	err := c.cc.Invoke(ctx, Update_Refetch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UpdateReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateReply)
+	err := c.cc.Invoke(ctx, Update_Reindex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Cancel(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Update_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Update_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsReply)
+	err := c.cc.Invoke(ctx, Update_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) ListFailures(ctx context.Context, in *ListFailuresRequest, opts ...grpc.CallOption) (*ListFailuresReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFailuresReply)
+	err := c.cc.Invoke(ctx, Update_ListFailures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *updateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsReply)
@@ -102,7 +200,19 @@ func (c *updateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc
 type UpdateServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Status(context.Context, *emptypb.Empty) (*StatusReply, error)
-	Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	WatchStatus(*emptypb.Empty, grpc.ServerStreamingServer[StatusReply]) error
+	// Update starts an update job and returns it without waiting for the end.
+	Update(context.Context, *emptypb.Empty) (*UpdateReply, error)
+	// Refetch starts a job fetching stored comics again and replacing them.
+	Refetch(context.Context, *RefetchRequest) (*UpdateReply, error)
+	// Reindex starts a job normalizing stored comics again with the current
+	// version of the words service, resuming one cut short.
+	Reindex(context.Context, *emptypb.Empty) (*UpdateReply, error)
+	// Cancel stops the running job and returns it once it has stopped.
+	Cancel(context.Context, *emptypb.Empty) (*Job, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsReply, error)
+	ListFailures(context.Context, *ListFailuresRequest) (*ListFailuresReply, error)
 	Stats(context.Context, *emptypb.Empty) (*StatsReply, error)
 	Drop(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUpdateServer()
@@ -121,9 +231,30 @@ func (UnimplementedUpdateServer) Ping(context.Context, *emptypb.Empty) (*emptypb
 func (UnimplementedUpdateServer) Status(context.Context, *emptypb.Empty) (*StatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
-func (UnimplementedUpdateServer) Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+func (UnimplementedUpdateServer) WatchStatus(*emptypb.Empty, grpc.ServerStreamingServer[StatusReply]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStatus not implemented")
+}
+func (UnimplementedUpdateServer) Update(context.Context, *emptypb.Empty) (*UpdateReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedUpdateServer) Refetch(context.Context, *RefetchRequest) (*UpdateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refetch not implemented")
+}
+func (UnimplementedUpdateServer) Reindex(context.Context, *emptypb.Empty) (*UpdateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
+func (UnimplementedUpdateServer) Cancel(context.Context, *emptypb.Empty) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedUpdateServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedUpdateServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedUpdateServer) ListFailures(context.Context, *ListFailuresRequest) (*ListFailuresReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFailures not implemented")
+}
 func (UnimplementedUpdateServer) Stats(context.Context, *emptypb.Empty) (*StatsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
@@ -187,6 +318,17 @@ func _Update_Status_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_WatchStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UpdateServer).WatchStatus(m, &grpc.GenericServerStream[emptypb.Empty, StatusReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchStatusServer = grpc.ServerStreamingServer[StatusReply]
+
 func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -205,6 +347,114 @@ func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_Refetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Refetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Refetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Refetch(ctx, req.(*RefetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Reindex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Reindex(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Cancel(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_ListFailures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailuresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).ListFailures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_ListFailures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).ListFailures(ctx, req.(*ListFailuresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Update_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -260,6 +510,30 @@ var Update_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _Update_Update_Handler,
 		},
+		{
+			MethodName: "Refetch",
+			Handler:    _Update_Refetch_Handler,
+		},
+		{
+			MethodName: "Reindex",
+			Handler:    _Update_Reindex_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Update_Cancel_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Update_GetJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _Update_ListJobs_Handler,
+		},
+		{
+			MethodName: "ListFailures",
+			Handler:    _Update_ListFailures_Handler,
+		},
 		{
 			MethodName: "Stats",
 			Handler:    _Update_Stats_Handler,
@@ -269,6 +543,12 @@ var Update_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Update_Drop_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStatus",
+			Handler:       _Update_WatchStatus_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "proto/update/update.proto",
 }
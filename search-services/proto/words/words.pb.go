@@ -23,8 +23,12 @@ const (
 )
 
 type WordsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Phrase string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	// ISO 639-1 code of the phrase language, "en" or "ru"; detected when empty.
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	// Normalization profile configured in the words service; "default" when empty.
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,16 +70,121 @@ func (x *WordsRequest) GetPhrase() string {
 	return ""
 }
 
-type WordsReply struct {
+func (x *WordsRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *WordsRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// Token is a word of the phrase as written with its byte offsets. Stem is
+// empty for dropped words; stop_word tells stop words from words dropped by
+// length, number or custom filters of the profile.
+type Token struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	Surface       string                 `protobuf:"bytes,1,opt,name=surface,proto3" json:"surface,omitempty"`
+	Stem          string                 `protobuf:"bytes,2,opt,name=stem,proto3" json:"stem,omitempty"`
+	Position      int32                  `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	Start         int32                  `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	StopWord      bool                   `protobuf:"varint,6,opt,name=stop_word,json=stopWord,proto3" json:"stop_word,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_proto_words_words_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Token) GetSurface() string {
+	if x != nil {
+		return x.Surface
+	}
+	return ""
+}
+
+func (x *Token) GetStem() string {
+	if x != nil {
+		return x.Stem
+	}
+	return ""
+}
+
+func (x *Token) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Token) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Token) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Token) GetStopWord() bool {
+	if x != nil {
+		return x.StopWord
+	}
+	return false
+}
+
+type WordsReply struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Words     []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	Positions []int32                `protobuf:"varint,2,rep,packed,name=positions,proto3" json:"positions,omitempty"`
+	Language  string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	// every word of the phrase, dropped ones included
+	Tokens        []*Token `protobuf:"bytes,4,rep,name=tokens,proto3" json:"tokens,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WordsReply) Reset() {
 	*x = WordsReply{}
-	mi := &file_proto_words_words_proto_msgTypes[1]
+	mi := &file_proto_words_words_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -87,7 +196,7 @@ func (x *WordsReply) String() string {
 func (*WordsReply) ProtoMessage() {}
 
 func (x *WordsReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_words_words_proto_msgTypes[1]
+	mi := &file_proto_words_words_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -100,7 +209,7 @@ func (x *WordsReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WordsReply.ProtoReflect.Descriptor instead.
 func (*WordsReply) Descriptor() ([]byte, []int) {
-	return file_proto_words_words_proto_rawDescGZIP(), []int{1}
+	return file_proto_words_words_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *WordsReply) GetWords() []string {
@@ -110,19 +219,356 @@ func (x *WordsReply) GetWords() []string {
 	return nil
 }
 
+func (x *WordsReply) GetPositions() []int32 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *WordsReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *WordsReply) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type BatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phrases       []*WordsRequest        `protobuf:"bytes,1,rep,name=phrases,proto3" json:"phrases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchRequest) GetPhrases() []*WordsRequest {
+	if x != nil {
+		return x.Phrases
+	}
+	return nil
+}
+
+type BatchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replies       []*WordsReply          `protobuf:"bytes,1,rep,name=replies,proto3" json:"replies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchReply) Reset() {
+	*x = BatchReply{}
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReply) ProtoMessage() {}
+
+func (x *BatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReply.ProtoReflect.Descriptor instead.
+func (*BatchReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchReply) GetReplies() []*WordsReply {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
+type Expansion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stem          string                 `protobuf:"bytes,1,opt,name=stem,proto3" json:"stem,omitempty"`
+	Terms         []string               `protobuf:"bytes,2,rep,name=terms,proto3" json:"terms,omitempty"`
+	Positions     []int32                `protobuf:"varint,3,rep,packed,name=positions,proto3" json:"positions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Expansion) Reset() {
+	*x = Expansion{}
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Expansion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expansion) ProtoMessage() {}
+
+func (x *Expansion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expansion.ProtoReflect.Descriptor instead.
+func (*Expansion) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Expansion) GetStem() string {
+	if x != nil {
+		return x.Stem
+	}
+	return ""
+}
+
+func (x *Expansion) GetTerms() []string {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+func (x *Expansion) GetPositions() []int32 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+type ExpandReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Expansions    []*Expansion           `protobuf:"bytes,1,rep,name=expansions,proto3" json:"expansions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandReply) Reset() {
+	*x = ExpandReply{}
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandReply) ProtoMessage() {}
+
+func (x *ExpandReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandReply.ProtoReflect.Descriptor instead.
+func (*ExpandReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExpandReply) GetExpansions() []*Expansion {
+	if x != nil {
+		return x.Expansions
+	}
+	return nil
+}
+
+type VersionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Normalization profile configured in the words service; "default" when empty.
+	Profile       string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionRequest) Reset() {
+	*x = VersionRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionRequest) ProtoMessage() {}
+
+func (x *VersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionRequest.ProtoReflect.Descriptor instead.
+func (*VersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VersionRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// version changes whenever the profile would give other stems for the same text
+type VersionReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionReply) Reset() {
+	*x = VersionReply{}
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionReply) ProtoMessage() {}
+
+func (x *VersionReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionReply.ProtoReflect.Descriptor instead.
+func (*VersionReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VersionReply) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
 var File_proto_words_words_proto protoreflect.FileDescriptor
 
 const file_proto_words_words_proto_rawDesc = "" +
 	"\n" +
-	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"&\n" +
+	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"\\\n" +
 	"\fWordsRequest\x12\x16\n" +
-	"\x06phrase\x18\x01 \x01(\tR\x06phrase\"\"\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"\x96\x01\n" +
+	"\x05Token\x12\x18\n" +
+	"\asurface\x18\x01 \x01(\tR\asurface\x12\x12\n" +
+	"\x04stem\x18\x02 \x01(\tR\x04stem\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\x05R\bposition\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\x12\x1b\n" +
+	"\tstop_word\x18\x06 \x01(\bR\bstopWord\"\x82\x01\n" +
 	"\n" +
 	"WordsReply\x12\x14\n" +
-	"\x05words\x18\x01 \x03(\tR\x05words2s\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words\x12\x1c\n" +
+	"\tpositions\x18\x02 \x03(\x05R\tpositions\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12$\n" +
+	"\x06tokens\x18\x04 \x03(\v2\f.words.TokenR\x06tokens\"=\n" +
+	"\fBatchRequest\x12-\n" +
+	"\aphrases\x18\x01 \x03(\v2\x13.words.WordsRequestR\aphrases\"9\n" +
+	"\n" +
+	"BatchReply\x12+\n" +
+	"\areplies\x18\x01 \x03(\v2\x11.words.WordsReplyR\areplies\"S\n" +
+	"\tExpansion\x12\x12\n" +
+	"\x04stem\x18\x01 \x01(\tR\x04stem\x12\x14\n" +
+	"\x05terms\x18\x02 \x03(\tR\x05terms\x12\x1c\n" +
+	"\tpositions\x18\x03 \x03(\x05R\tpositions\"?\n" +
+	"\vExpandReply\x120\n" +
+	"\n" +
+	"expansions\x18\x01 \x03(\v2\x10.words.ExpansionR\n" +
+	"expansions\"*\n" +
+	"\x0eVersionRequest\x12\x18\n" +
+	"\aprofile\x18\x01 \x01(\tR\aprofile\"(\n" +
+	"\fVersionReply\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion2\xd4\x02\n" +
 	"\x05Words\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
-	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
+	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00\x125\n" +
+	"\tNormBatch\x12\x13.words.BatchRequest\x1a\x11.words.BatchReply\"\x00\x12:\n" +
+	"\n" +
+	"NormStream\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00(\x010\x01\x123\n" +
+	"\x06Expand\x12\x13.words.WordsRequest\x1a\x12.words.ExpandReply\"\x00\x127\n" +
+	"\aVersion\x12\x15.words.VersionRequest\x1a\x13.words.VersionReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
 
 var (
 	file_proto_words_words_proto_rawDescOnce sync.Once
@@ -136,22 +582,41 @@ func file_proto_words_words_proto_rawDescGZIP() []byte {
 	return file_proto_words_words_proto_rawDescData
 }
 
-var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_words_words_proto_goTypes = []any{
-	(*WordsRequest)(nil),  // 0: words.WordsRequest
-	(*WordsReply)(nil),    // 1: words.WordsReply
-	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+	(*WordsRequest)(nil),   // 0: words.WordsRequest
+	(*Token)(nil),          // 1: words.Token
+	(*WordsReply)(nil),     // 2: words.WordsReply
+	(*BatchRequest)(nil),   // 3: words.BatchRequest
+	(*BatchReply)(nil),     // 4: words.BatchReply
+	(*Expansion)(nil),      // 5: words.Expansion
+	(*ExpandReply)(nil),    // 6: words.ExpandReply
+	(*VersionRequest)(nil), // 7: words.VersionRequest
+	(*VersionReply)(nil),   // 8: words.VersionReply
+	(*emptypb.Empty)(nil),  // 9: google.protobuf.Empty
 }
 var file_proto_words_words_proto_depIdxs = []int32{
-	2, // 0: words.Words.Ping:input_type -> google.protobuf.Empty
-	0, // 1: words.Words.Norm:input_type -> words.WordsRequest
-	2, // 2: words.Words.Ping:output_type -> google.protobuf.Empty
-	1, // 3: words.Words.Norm:output_type -> words.WordsReply
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: words.WordsReply.tokens:type_name -> words.Token
+	0,  // 1: words.BatchRequest.phrases:type_name -> words.WordsRequest
+	2,  // 2: words.BatchReply.replies:type_name -> words.WordsReply
+	5,  // 3: words.ExpandReply.expansions:type_name -> words.Expansion
+	9,  // 4: words.Words.Ping:input_type -> google.protobuf.Empty
+	0,  // 5: words.Words.Norm:input_type -> words.WordsRequest
+	3,  // 6: words.Words.NormBatch:input_type -> words.BatchRequest
+	0,  // 7: words.Words.NormStream:input_type -> words.WordsRequest
+	0,  // 8: words.Words.Expand:input_type -> words.WordsRequest
+	7,  // 9: words.Words.Version:input_type -> words.VersionRequest
+	9,  // 10: words.Words.Ping:output_type -> google.protobuf.Empty
+	2,  // 11: words.Words.Norm:output_type -> words.WordsReply
+	4,  // 12: words.Words.NormBatch:output_type -> words.BatchReply
+	2,  // 13: words.Words.NormStream:output_type -> words.WordsReply
+	6,  // 14: words.Words.Expand:output_type -> words.ExpandReply
+	8,  // 15: words.Words.Version:output_type -> words.VersionReply
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_proto_words_words_proto_init() }
@@ -165,7 +630,7 @@ func file_proto_words_words_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_words_words_proto_rawDesc), len(file_proto_words_words_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -2,9 +2,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -26,18 +29,23 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	return &DB{log: log, conn: db}, nil
 }
 
-func (db *DB) SearchComics(ctx context.Context, words []string, limit int) ([]core.Comic, int, error) {
-	if len(words) == 0 {
+func (db *DB) SearchComics(ctx context.Context, q *core.Query, limit, offset int) ([]core.Comic, int, error) {
+	if q == nil {
 		return nil, 0, nil
 	}
 
-	query := `
+	args := []any{pq.StringArray(q.PositiveTerms())}
+	cond := buildCondition(q, &args)
+
+	query := fmt.Sprintf(`
   SELECT id,
-         img_url AS url
+         img_url AS url,
+         title
   FROM (
    SELECT
     id,
     img_url,
+    title,
     cardinality(
      ARRAY(
       SELECT unnest(words)
@@ -46,78 +54,158 @@ func (db *DB) SearchComics(ctx context.Context, words []string, limit int) ([]co
      )
     ) AS match_count
    FROM comics
-   WHERE words && $1
+   WHERE status = 'ok' AND %s
   ) AS ranked
-  WHERE match_count > 0
   ORDER BY match_count DESC, id ASC
-  LIMIT $2;
- `
+  LIMIT $%d OFFSET $%d;
+ `, cond, len(args)+1, len(args)+2)
 	var comics []core.Comic
-	if err := db.conn.SelectContext(ctx, &comics, query, pq.StringArray(words), limit); err != nil {
+	if err := db.conn.SelectContext(ctx, &comics, query, append(args, limit, offset)...); err != nil {
 		return nil, 0, err
 	}
 
-	countQuery := `
-  SELECT COUNT(*)
-  FROM (
-   SELECT
-    cardinality(
-     ARRAY(
-      SELECT unnest(words)
-      INTERSECT
-      SELECT unnest($1::text[])
-     )
-    ) AS match_count
-   FROM comics
-   WHERE words && $1
-  ) AS ranked
-  WHERE match_count > 0;
- `
+	var countArgs []any
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM comics WHERE status = 'ok' AND %s`, buildCondition(q, &countArgs))
 	var total int
-	if err := db.conn.GetContext(ctx, &total, countQuery, pq.StringArray(words)); err != nil {
+	if err := db.conn.GetContext(ctx, &total, countQuery, countArgs...); err != nil {
 		return nil, 0, err
 	}
+	return comics, total, nil
+}
 
-	// обрезаем total по limit, как ожидает тест
-	if limit > 0 && total > limit {
-		total = limit
+// buildCondition renders the query tree as a WHERE clause over the words
+// array, appending term arrays to args as positional parameters. A term
+// matches by any of its stems or their variants, a phrase needs all its
+// stems. Leaves restricted to some fields only look at the words of those
+// fields.
+func buildCondition(q *core.Query, args *[]any) string {
+	switch q.Op {
+	case core.QueryTerm, core.QueryPhrase:
+		terms, op := q.TermsWithVariants(), "&&"
+		if q.Op == core.QueryPhrase {
+			terms, op = q.Terms, "@>"
+		}
+		*args = append(*args, pq.StringArray(terms))
+		if len(q.Fields) == 0 {
+			return fmt.Sprintf("words %s $%d::text[]", op, len(*args))
+		}
+		fields := make(pq.Int64Array, 0, len(q.Fields))
+		for _, f := range q.Fields {
+			fields = append(fields, int64(f))
+		}
+		*args = append(*args, fields)
+		return fmt.Sprintf(
+			"ARRAY(SELECT w FROM unnest(words, fields) AS t(w, f) WHERE f = ANY($%d::smallint[])) %s $%d::text[]",
+			len(*args), op, len(*args)-1)
+	case core.QueryNot:
+		return "NOT (" + buildCondition(q.Children[0], args) + ")"
+	case core.QueryAnd, core.QueryOr:
+		sep := " AND "
+		if q.Op == core.QueryOr {
+			sep = " OR "
+		}
+		parts := make([]string, 0, len(q.Children))
+		for _, c := range q.Children {
+			parts = append(parts, buildCondition(c, args))
+		}
+		return "(" + strings.Join(parts, sep) + ")"
 	}
-	return comics, total, nil
+	return "FALSE"
 }
 
-func (db *DB) LoadIndexData(ctx context.Context) (map[int][]string, error) {
-	rows, err := db.conn.QueryContext(ctx, `SELECT id, words FROM comics`)
+func (db *DB) LoadIndexData(ctx context.Context, since time.Time) (map[int][]core.Token, time.Time, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, words, positions, fields, updated_at FROM comics WHERE status = 'ok' AND updated_at >= $1`, since)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, watermark, err := db.scanIndexData(rows)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if watermark.IsZero() {
+		watermark = since
+	}
+	return data, watermark, nil
+}
+
+func (db *DB) LoadIndexDataByIDs(ctx context.Context, ids []int) (map[int][]core.Token, error) {
+	if len(ids) == 0 {
+		return map[int][]core.Token{}, nil
+	}
+	query, args, err := sqlx.In(`SELECT id, words, positions, fields, updated_at FROM comics WHERE status = 'ok' AND id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IN query: %w", err)
+	}
+	rows, err := db.conn.QueryContext(ctx, db.conn.Rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
+	data, _, err := db.scanIndexData(rows)
+	return data, err
+}
+
+func (db *DB) scanIndexData(rows *sql.Rows) (map[int][]core.Token, time.Time, error) {
 	defer func() {
 		if err := rows.Close(); err != nil {
 			db.log.Error("failed to close rows", "error", err)
 		}
 	}()
 
-	data := make(map[int][]string)
+	data := make(map[int][]core.Token)
+	var watermark time.Time
 	for rows.Next() {
 		var id int
 		var words string
-		if err := rows.Scan(&id, &words); err != nil {
-			return nil, err
+		var positions, fields pq.Int64Array
+		var updatedAt time.Time
+		if err := rows.Scan(&id, &words, &positions, &fields, &updatedAt); err != nil {
+			return nil, time.Time{}, err
 		}
-		words = strings.Trim(words, "{}")
-		if words == "" {
-			data[id] = []string{}
-		} else {
-			data[id] = strings.Split(words, ",")
+		if updatedAt.After(watermark) {
+			watermark = updatedAt
 		}
+		data[id] = toTokens(words, positions, fields)
 	}
-	return data, rows.Err()
+	return data, watermark, rows.Err()
+}
+
+// toTokens pairs stems with their positions and fields. Rows written before
+// positions were stored fall back to the array index, rows without fields
+// keep the unknown field.
+func toTokens(words string, positions, fields pq.Int64Array) []core.Token {
+	words = strings.Trim(words, "{}")
+	if words == "" {
+		return []core.Token{}
+	}
+	stems := strings.Split(words, ",")
+	tokens := make([]core.Token, 0, len(stems))
+	for i, stem := range stems {
+		t := core.Token{Stem: stem, Pos: i}
+		if len(positions) == len(stems) {
+			t.Pos = int(positions[i])
+		}
+		if len(fields) == len(stems) {
+			t.Field = core.Field(fields[i])
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func (db *DB) IDs(ctx context.Context) ([]int, error) {
+	var ids []int
+	if err := db.conn.SelectContext(ctx, &ids, `SELECT id FROM comics WHERE status = 'ok' ORDER BY id`); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (db *DB) GetComicsByIDs(ctx context.Context, ids []int) ([]core.Comic, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	query, args, err := sqlx.In(`SELECT id, img_url as url FROM comics WHERE id IN (?) ORDER BY id`, ids)
+	query, args, err := sqlx.In(`SELECT id, img_url as url, title FROM comics WHERE status = 'ok' AND id IN (?) ORDER BY id`, ids)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create IN query: %w", err)
 	}
@@ -130,3 +218,69 @@ func (db *DB) GetComicsByIDs(ctx context.Context, ids []int) ([]core.Comic, erro
 	}
 	return comics, nil
 }
+
+func (db *DB) GetComicTexts(ctx context.Context, ids []int) (map[int]core.ComicText, error) {
+	texts := make(map[int]core.ComicText, len(ids))
+	if len(ids) == 0 {
+		return texts, nil
+	}
+	query, args, err := sqlx.In(`SELECT id, title, alt, transcript, words FROM comics WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IN query: %w", err)
+	}
+	rows, err := db.conn.QueryContext(ctx, db.conn.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			db.log.Error("failed to close rows", "error", err)
+		}
+	}()
+
+	for rows.Next() {
+		var id int
+		var text core.ComicText
+		var words pq.StringArray
+		if err := rows.Scan(&id, &text.Title, &text.Alt, &text.Transcript, &words); err != nil {
+			return nil, err
+		}
+		text.Words = words
+		texts[id] = text
+	}
+	return texts, rows.Err()
+}
+
+type comicRow struct {
+	ID         int          `db:"id"`
+	URL        string       `db:"img_url"`
+	Title      string       `db:"title"`
+	Alt        string       `db:"alt"`
+	Transcript string       `db:"transcript"`
+	Published  sql.NullTime `db:"published"`
+	PageURL    string       `db:"page_url"`
+	FetchedAt  sql.NullTime `db:"fetched_at"`
+}
+
+func (db *DB) GetComic(ctx context.Context, id int) (core.ComicInfo, error) {
+	var row comicRow
+	err := db.conn.GetContext(ctx, &row,
+		`SELECT id, img_url, title, alt, transcript, published, page_url, fetched_at
+         FROM comics WHERE status = 'ok' AND id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.ComicInfo{}, core.ErrNotFound
+		}
+		return core.ComicInfo{}, err
+	}
+	return core.ComicInfo{
+		ID:         row.ID,
+		URL:        row.URL,
+		Title:      row.Title,
+		Alt:        row.Alt,
+		Transcript: row.Transcript,
+		Published:  row.Published.Time,
+		PageURL:    row.PageURL,
+		FetchedAt:  row.FetchedAt.Time,
+	}, nil
+}
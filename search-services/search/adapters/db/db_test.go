@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/lib/pq"
 	"log/slog"
 	"os"
+	"time"
 	"yadro.com/course/search/core"
 )
 
@@ -36,16 +38,21 @@ func TestSearchComics(t *testing.T) {
 	defer closeFn()
 
 	ctx := context.Background()
-	words := []string{"foo", "bar"}
+	q := &core.Query{Op: core.QueryOr, Children: []*core.Query{
+		{Op: core.QueryTerm, Terms: []string{"foo"}},
+		{Op: core.QueryTerm, Terms: []string{"bar"}},
+	}}
 	limit := 2
 
 	searchQuery := regexp.QuoteMeta(`
   SELECT id,
-         img_url AS url
+         img_url AS url,
+         title
   FROM (
    SELECT
     id,
     img_url,
+    title,
     cardinality(
      ARRAY(
       SELECT unnest(words)
@@ -54,11 +61,10 @@ func TestSearchComics(t *testing.T) {
      )
     ) AS match_count
    FROM comics
-   WHERE words && $1
+   WHERE status = 'ok' AND (words && $2::text[] OR words && $3::text[])
   ) AS ranked
-  WHERE match_count > 0
   ORDER BY match_count DESC, id ASC
-  LIMIT $2;
+  LIMIT $4 OFFSET $5;
  `)
 
 	rows := sqlmock.NewRows([]string{"id", "url"}).
@@ -66,33 +72,18 @@ func TestSearchComics(t *testing.T) {
 		AddRow(2, "url2")
 
 	mock.ExpectQuery(searchQuery).
-		WithArgs(sqlmock.AnyArg(), limit).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), limit, 0).
 		WillReturnRows(rows)
 
-	countQuery := regexp.QuoteMeta(`
-  SELECT COUNT(*)
-  FROM (
-   SELECT
-    cardinality(
-     ARRAY(
-      SELECT unnest(words)
-      INTERSECT
-      SELECT unnest($1::text[])
-     )
-    ) AS match_count
-   FROM comics
-   WHERE words && $1
-  ) AS ranked
-  WHERE match_count > 0;
- `)
+	countQuery := regexp.QuoteMeta(`SELECT COUNT(*) FROM comics WHERE status = 'ok' AND (words && $1::text[] OR words && $2::text[])`)
 
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(5)
 
 	mock.ExpectQuery(countQuery).
-		WithArgs(sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(countRows)
 
-	got, total, err := db.SearchComics(ctx, words, limit)
+	got, total, err := db.SearchComics(ctx, q, limit, 0)
 	if err != nil {
 		t.Fatalf("SearchComics error: %v", err)
 	}
@@ -100,8 +91,8 @@ func TestSearchComics(t *testing.T) {
 	if len(got) != 2 {
 		t.Fatalf("expected 2 comics, got %d", len(got))
 	}
-	if total != limit {
-		t.Fatalf("expected total %d, got %d", limit, total)
+	if total != 5 {
+		t.Fatalf("expected total 5, got %d", total)
 	}
 	if got[0].ID != 1 || got[0].URL != "url1" {
 		t.Fatalf("unexpected first comic: %+v", got[0])
@@ -111,31 +102,62 @@ func TestSearchComics(t *testing.T) {
 	}
 }
 
+func TestBuildCondition(t *testing.T) {
+	q := &core.Query{Op: core.QueryAnd, Children: []*core.Query{
+		{Op: core.QueryPhrase, Terms: []string{"bobbi", "tabl"}},
+		{Op: core.QueryNot, Children: []*core.Query{
+			{Op: core.QueryTerm, Terms: []string{"drop"}, Variants: [][]core.Variant{{{Term: "delet", Weight: 0.5}}}},
+		}},
+		{Op: core.QueryTerm, Terms: []string{"xkcd"}, Fields: []core.Field{core.FieldTitle}},
+	}}
+
+	var args []any
+	got := buildCondition(q, &args)
+	want := "(words @> $1::text[] AND NOT (words && $2::text[]) AND " +
+		"ARRAY(SELECT w FROM unnest(words, fields) AS t(w, f) WHERE f = ANY($4::smallint[])) && $3::text[])"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if len(args) != 4 {
+		t.Fatalf("expected 4 args, got %d", len(args))
+	}
+	if terms := args[1].(pq.StringArray); len(terms) != 2 || terms[1] != "delet" {
+		t.Fatalf("expected term variants in the condition, got %v", terms)
+	}
+}
+
 func TestLoadIndexData(t *testing.T) {
 	db, mock, closeFn := newTestDB(t)
 	defer closeFn()
 
 	ctx := context.Background()
 
-	query := regexp.QuoteMeta(`SELECT id, words FROM comics`)
+	query := regexp.QuoteMeta(`SELECT id, words, positions, fields, updated_at FROM comics WHERE status = 'ok' AND updated_at >= $1`)
 
-	rows := sqlmock.NewRows([]string{"id", "words"}).
-		AddRow(1, "{foo,bar}").
-		AddRow(2, "{}").
-		AddRow(3, "{baz}")
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	latest := since.Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "words", "positions", "fields", "updated_at"}).
+		AddRow(1, "{foo,bar}", "{0,2}", "{1,3}", since).
+		AddRow(2, "{}", "{}", "{}", latest).
+		AddRow(3, "{baz}", "{}", "{}", since)
 
-	mock.ExpectQuery(query).WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(since).WillReturnRows(rows)
 
-	data, err := db.LoadIndexData(ctx)
+	data, watermark, err := db.LoadIndexData(ctx, since)
 	if err != nil {
 		t.Fatalf("LoadIndexData error: %v", err)
 	}
 
+	if !watermark.Equal(latest) {
+		t.Fatalf("expected watermark %v, got %v", latest, watermark)
+	}
+
 	if len(data) != 3 {
 		t.Fatalf("expected 3 records, got %d", len(data))
 	}
 
-	if len(data[1]) != 2 || data[1][0] != "foo" || data[1][1] != "bar" {
+	if len(data[1]) != 2 || data[1][0] != (core.Token{Stem: "foo", Pos: 0, Field: core.FieldTitle}) ||
+		data[1][1] != (core.Token{Stem: "bar", Pos: 2, Field: core.FieldTranscript}) {
 		t.Fatalf("unexpected data[1]: %#v", data[1])
 	}
 
@@ -143,7 +165,7 @@ func TestLoadIndexData(t *testing.T) {
 		t.Fatalf("expected empty slice for id=2, got %#v", data[2])
 	}
 
-	if len(data[3]) != 1 || data[3][0] != "baz" {
+	if len(data[3]) != 1 || data[3][0] != (core.Token{Stem: "baz", Pos: 0}) {
 		t.Fatalf("unexpected data[3]: %#v", data[3])
 	}
 
@@ -159,7 +181,7 @@ func TestGetComicsByIDs(t *testing.T) {
 	ctx := context.Background()
 	ids := []int{2, 5, 7}
 
-	queryRegex := `SELECT id, img_url as url FROM comics WHERE id IN \(.+\) ORDER BY id`
+	queryRegex := `SELECT id, img_url as url, title FROM comics WHERE status = 'ok' AND id IN \(.+\) ORDER BY id`
 
 	rows := sqlmock.NewRows([]string{"id", "url"}).
 		AddRow(2, "u2").
@@ -188,7 +210,7 @@ func TestGetComicsByIDs(t *testing.T) {
 		{ID: 7, URL: "u7"},
 	}
 	for i := range expected {
-		if comics[i] != expected[i] {
+		if comics[i].ID != expected[i].ID || comics[i].URL != expected[i].URL {
 			t.Fatalf("at %d expected %#v, got %#v", i, expected[i], comics[i])
 		}
 	}
@@ -213,13 +235,13 @@ func TestGetComicsByIDs_Empty(t *testing.T) {
 	}
 }
 
-func TestSearchComics_EmptyWords(t *testing.T) {
+func TestSearchComics_EmptyQuery(t *testing.T) {
 	db, _, closeFn := newTestDB(t)
 	defer closeFn()
 
 	ctx := context.Background()
 
-	comics, total, err := db.SearchComics(ctx, nil, 10)
+	comics, total, err := db.SearchComics(ctx, nil, 10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -227,3 +249,35 @@ func TestSearchComics_EmptyWords(t *testing.T) {
 		t.Fatalf("expected nil,0 got %#v,%d", comics, total)
 	}
 }
+
+func TestGetComic(t *testing.T) {
+	db, mock, closeFn := newTestDB(t)
+	defer closeFn()
+
+	ctx := context.Background()
+	published := time.Date(2007, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	query := `SELECT id, img_url, title, alt, transcript, published, page_url, fetched_at\s+FROM comics WHERE status = 'ok' AND id = \$1`
+	rows := sqlmock.NewRows([]string{"id", "img_url", "title", "alt", "transcript", "published", "page_url", "fetched_at"}).
+		AddRow(327, "https://imgs.xkcd.com/comics/exploits_of_a_mom.png", "Exploits of a Mom",
+			"Her daughter is named Help I'm trapped in a driver's license factory.", "",
+			published, "https://xkcd.com/327/", nil)
+	mock.ExpectQuery(query).WithArgs(327).WillReturnRows(rows)
+
+	c, err := db.GetComic(ctx, 327)
+	if err != nil {
+		t.Fatalf("GetComic error: %v", err)
+	}
+	if c.ID != 327 || c.Title != "Exploits of a Mom" || !c.Published.Equal(published) || !c.FetchedAt.IsZero() {
+		t.Fatalf("unexpected comic: %+v", c)
+	}
+
+	mock.ExpectQuery(query).WithArgs(100500).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := db.GetComic(ctx, 100500); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -2,10 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	searchpb "yadro.com/course/proto/search"
 	"yadro.com/course/search/core"
@@ -28,64 +30,98 @@ func (s *Server) Search(ctx context.Context, req *searchpb.SearchRequest) (*sear
 	params := core.SearchParams{
 		Phrase: req.GetPhrase(),
 		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+		Cursor: req.GetCursor(),
+		Fuzzy:  req.GetFuzzy(),
 	}
 
 	res, err := s.service.Search(ctx, params)
 	if err != nil {
-		switch err {
-		case core.ErrBadArguments:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		case core.ErrRequestTooLarge:
-			return nil, status.Error(codes.ResourceExhausted, err.Error())
-		default:
-			return nil, status.Error(codes.Internal, err.Error())
-		}
+		return nil, mapError(err)
 	}
-
-	resp := &searchpb.SearchReply{
-		Total: uint32(res.Total),
-	}
-
-	resp.Comics = make([]*searchpb.Comic, 0, len(res.Comics))
-	for _, c := range res.Comics {
-		resp.Comics = append(resp.Comics, &searchpb.Comic{
-			Id:  int32(c.ID),
-			Url: c.URL,
-		})
-	}
-
-	return resp, nil
+	return toReply(res), nil
 }
 
 func (s *Server) ISearch(ctx context.Context, req *searchpb.SearchRequest) (*searchpb.SearchReply, error) {
 	params := core.SearchParams{
 		Phrase: req.GetPhrase(),
 		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+		Cursor: req.GetCursor(),
+		Fuzzy:  req.GetFuzzy(),
 	}
 
 	res, err := s.service.ISearch(ctx, params)
 	if err != nil {
-		switch err {
-		case core.ErrBadArguments:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		case core.ErrRequestTooLarge:
-			return nil, status.Error(codes.ResourceExhausted, err.Error())
-		default:
-			return nil, status.Error(codes.Internal, err.Error())
-		}
+		return nil, mapError(err)
 	}
+	return toReply(res), nil
+}
 
+func (s *Server) GetComic(ctx context.Context, req *searchpb.GetComicRequest) (*searchpb.ComicInfo, error) {
+	c, err := s.service.GetComic(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	reply := &searchpb.ComicInfo{
+		Id:         int32(c.ID),
+		Url:        c.URL,
+		Title:      c.Title,
+		Alt:        c.Alt,
+		Transcript: c.Transcript,
+		PageUrl:    c.PageURL,
+	}
+	if !c.Published.IsZero() {
+		reply.Published = timestamppb.New(c.Published)
+	}
+	if !c.FetchedAt.IsZero() {
+		reply.FetchedAt = timestamppb.New(c.FetchedAt)
+	}
+	return reply, nil
+}
+
+func (s *Server) Suggest(ctx context.Context, req *searchpb.SuggestRequest) (*searchpb.SuggestReply, error) {
+	completions, err := s.service.Suggest(ctx, req.GetPrefix(), int(req.GetLimit()))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	reply := &searchpb.SuggestReply{Completions: make([]*searchpb.Completion, 0, len(completions))}
+	for _, c := range completions {
+		reply.Completions = append(reply.Completions, &searchpb.Completion{Term: c.Term, Count: uint32(c.Count)})
+	}
+	return reply, nil
+}
+
+func toReply(res core.SearchResult) *searchpb.SearchReply {
 	resp := &searchpb.SearchReply{
-		Total: uint32(res.Total),
+		Total:       uint32(res.Total),
+		NextCursor:  res.NextCursor,
+		Suggestions: res.Suggestions,
 	}
 
 	resp.Comics = make([]*searchpb.Comic, 0, len(res.Comics))
 	for _, c := range res.Comics {
 		resp.Comics = append(resp.Comics, &searchpb.Comic{
-			Id:  int32(c.ID),
-			Url: c.URL,
+			Id:           int32(c.ID),
+			Url:          c.URL,
+			Score:        c.Score,
+			Title:        c.Title,
+			Snippet:      c.Snippet,
+			MatchedTerms: c.MatchedTerms,
 		})
 	}
+	return resp
+}
 
-	return resp, nil
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrBadArguments):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrRequestTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
 }
@@ -2,8 +2,8 @@ package indexer
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
-	"sync/atomic"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -14,6 +14,11 @@ const (
 	defaultDebounce = 10 * time.Second
 )
 
+type dbUpdatedEvent struct {
+	IDs       []int `json:"ids"`
+	Reconcile bool  `json:"reconcile"`
+}
+
 type EventIndexer struct {
 	log      *slog.Logger
 	svc      core.Searcher
@@ -23,7 +28,8 @@ type EventIndexer struct {
 	cancel context.CancelFunc
 	sub    *nats.Subscription
 
-	pending int32
+	pending   map[int]struct{}
+	reconcile bool
 }
 
 func NewEventIndexer(log *slog.Logger, svc core.Searcher, nc *nats.Conn) *EventIndexer {
@@ -32,6 +38,7 @@ func NewEventIndexer(log *slog.Logger, svc core.Searcher, nc *nats.Conn) *EventI
 		svc:      svc,
 		nc:       nc,
 		debounce: defaultDebounce,
+		pending:  make(map[int]struct{}),
 	}
 }
 
@@ -69,19 +76,13 @@ func (i *EventIndexer) Start(ctx context.Context) error {
 				return
 
 			case <-ticker.C:
-				if atomic.LoadInt32(&i.pending) == 1 {
-					i.log.Info("rebuilding index after db update events")
-					if err := i.svc.RebuildIndex(ctx); err != nil {
-						i.log.Error("index rebuild failed", "error", err)
-					}
-					atomic.StoreInt32(&i.pending, 0)
-				}
+				i.flush(ctx)
 
-			case _, ok := <-ch:
+			case msg, ok := <-ch:
 				if !ok {
 					return
 				}
-				atomic.StoreInt32(&i.pending, 1)
+				i.handle(msg)
 			}
 		}
 	}()
@@ -89,6 +90,53 @@ func (i *EventIndexer) Start(ctx context.Context) error {
 	return nil
 }
 
+func (i *EventIndexer) handle(msg *nats.Msg) {
+	var event dbUpdatedEvent
+	if len(msg.Data) == 0 {
+		i.reconcile = true
+		return
+	}
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		i.log.Warn("malformed db updated event, scheduling reconcile", "error", err)
+		i.reconcile = true
+		return
+	}
+	if event.Reconcile {
+		i.reconcile = true
+		return
+	}
+	for _, id := range event.IDs {
+		i.pending[id] = struct{}{}
+	}
+}
+
+func (i *EventIndexer) flush(ctx context.Context) {
+	if i.reconcile {
+		i.log.Info("rebuilding index after reconcile request")
+		if err := i.svc.RebuildIndex(ctx); err != nil {
+			i.log.Error("index rebuild failed", "error", err)
+			return
+		}
+		i.reconcile = false
+		clear(i.pending)
+		return
+	}
+	if len(i.pending) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(i.pending))
+	for id := range i.pending {
+		ids = append(ids, id)
+	}
+	i.log.Info("merging updated comics into index", "comics", len(ids))
+	if err := i.svc.UpdateIndex(ctx, ids); err != nil {
+		i.log.Error("index update failed", "error", err)
+		return
+	}
+	clear(i.pending)
+}
+
 func (i *EventIndexer) Stop() {
 	if i.cancel != nil {
 		i.cancel()
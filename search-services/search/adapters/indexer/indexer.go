@@ -2,6 +2,7 @@ package indexer
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -28,26 +29,42 @@ func (i *Indexer) Start(ctx context.Context) {
 	i.cancel = cancel
 
 	go func() {
-		if err := i.svc.RebuildIndex(ctx); err != nil {
-			i.log.Error("initial index rebuild failed", "error", err)
+		if err := i.svc.RestoreIndex(ctx); err != nil {
+			if !errors.Is(err, core.ErrNoSnapshot) {
+				i.log.Warn("index restore failed, rebuilding", "error", err)
+			}
+			if err := i.svc.RebuildIndex(ctx); err != nil {
+				i.log.Error("initial index rebuild failed", "error", err)
+			}
 		}
+		i.save(ctx)
+
 		ticker := time.NewTicker(i.ttl)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-ctx.Done():
+				i.save(context.WithoutCancel(ctx))
 				i.log.Info("indexer stopped")
 				return
 			case <-ticker.C:
-				if err := i.svc.RebuildIndex(ctx); err != nil {
-					i.log.Error("index rebuild failed", "error", err)
+				if err := i.svc.SyncIndex(ctx); err != nil {
+					i.log.Error("index sync failed", "error", err)
+					continue
 				}
+				i.save(ctx)
 			}
 		}
 	}()
 }
 
+func (i *Indexer) save(ctx context.Context) {
+	if err := i.svc.SaveIndex(ctx); err != nil {
+		i.log.Error("index snapshot save failed", "error", err)
+	}
+}
+
 func (i *Indexer) Stop() {
 	if i.cancel != nil {
 		i.cancel()
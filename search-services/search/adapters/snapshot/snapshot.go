@@ -0,0 +1,292 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+const (
+	magic   = "XKIX"
+	version = 3
+
+	maxChunk = 1 << 20
+)
+
+var ErrBadSnapshot = errors.New("index snapshot is corrupted")
+
+type Store struct {
+	log  *slog.Logger
+	path string
+}
+
+func New(log *slog.Logger, path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("empty snapshot path")
+	}
+	return &Store{log: log, path: path}, nil
+}
+
+func (s *Store) Save(snapshot core.Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Warn("failed to remove temporary snapshot", "error", err)
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if err := encode(w, snapshot); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.log.Debug("index snapshot saved", "path", s.path, "docs", snapshot.Index.Docs())
+	return nil
+}
+
+func (s *Store) Load() (core.Snapshot, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return core.Snapshot{}, core.ErrNoSnapshot
+		}
+		return core.Snapshot{}, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			s.log.Warn("failed to close snapshot", "error", err)
+		}
+	}()
+	return decode(bufio.NewReader(f))
+}
+
+type writer struct {
+	w   io.Writer
+	crc uint32
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (w *writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	w.crc = crc32.Update(w.crc, crc32.IEEETable, p)
+	_, w.err = w.w.Write(p)
+}
+
+func (w *writer) uvarint(v uint64) {
+	n := binary.PutUvarint(w.buf[:], v)
+	w.write(w.buf[:n])
+}
+
+func (w *writer) varint(v int64) {
+	n := binary.PutVarint(w.buf[:], v)
+	w.write(w.buf[:n])
+}
+
+func (w *writer) string(v string) {
+	w.uvarint(uint64(len(v)))
+	w.write([]byte(v))
+}
+
+func encode(out io.Writer, snapshot core.Snapshot) error {
+	idx := snapshot.Index
+	if idx == nil {
+		idx = core.NewIndex()
+	}
+
+	w := &writer{w: out}
+	w.write([]byte(magic))
+	w.uvarint(version)
+	var watermark int64
+	if !snapshot.Watermark.IsZero() {
+		watermark = snapshot.Watermark.UnixNano()
+	}
+	w.varint(watermark)
+
+	ids := make([]int, 0, len(idx.DocLens))
+	for id := range idx.DocLens {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	w.uvarint(uint64(len(ids)))
+	for _, id := range ids {
+		w.uvarint(uint64(id))
+		w.uvarint(uint64(idx.DocLens[id]))
+	}
+
+	terms := make([]string, 0, len(idx.Postings))
+	for term := range idx.Postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	w.uvarint(uint64(len(terms)))
+	for _, term := range terms {
+		postings := idx.Postings[term]
+		w.string(term)
+		w.uvarint(uint64(len(postings)))
+		prev := 0
+		for _, p := range postings {
+			w.uvarint(uint64(p.ID - prev))
+			w.uvarint(uint64(len(p.Positions)))
+			last := 0
+			for k, pos := range p.Positions {
+				w.uvarint(uint64(pos - last))
+				w.uvarint(uint64(p.Fields[k]))
+				last = pos
+			}
+			prev = p.ID
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], w.crc)
+	_, err := out.Write(sum[:])
+	return err
+}
+
+type reader struct {
+	r   *bufio.Reader
+	crc uint32
+	err error
+}
+
+func (r *reader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	r.crc = crc32.Update(r.crc, crc32.IEEETable, []byte{b})
+	return b, nil
+}
+
+func (r *reader) read(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > maxChunk {
+		r.err = fmt.Errorf("chunk of %d bytes is too large", n)
+		return nil
+	}
+	p := make([]byte, n)
+	if _, r.err = io.ReadFull(r.r, p); r.err != nil {
+		return nil
+	}
+	r.crc = crc32.Update(r.crc, crc32.IEEETable, p)
+	return p
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	var v uint64
+	v, r.err = binary.ReadUvarint(r)
+	return v
+}
+
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	var v int64
+	v, r.err = binary.ReadVarint(r)
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.read(r.uvarint()))
+}
+
+func decode(in *bufio.Reader) (core.Snapshot, error) {
+	r := &reader{r: in}
+	if string(r.read(uint64(len(magic)))) != magic {
+		return core.Snapshot{}, ErrBadSnapshot
+	}
+	if v := r.uvarint(); r.err == nil && v != version {
+		return core.Snapshot{}, fmt.Errorf("%w: unsupported version %d", ErrBadSnapshot, v)
+	}
+	var watermark time.Time
+	if v := r.varint(); v != 0 {
+		watermark = time.Unix(0, v).UTC()
+	}
+
+	idx := core.NewIndex()
+	docs := r.uvarint()
+	for i := uint64(0); i < docs && r.err == nil; i++ {
+		id := int(r.uvarint())
+		length := int(r.uvarint())
+		idx.DocLens[id] = length
+		idx.TotalLen += length
+	}
+
+	terms := r.uvarint()
+	for i := uint64(0); i < terms && r.err == nil; i++ {
+		term := r.string()
+		n := r.uvarint()
+		postings := make([]core.Posting, 0, min(n, 1<<16))
+		prev := 0
+		for j := uint64(0); j < n && r.err == nil; j++ {
+			id := prev + int(r.uvarint())
+			tf := r.uvarint()
+			positions := make([]int, 0, min(tf, 1<<16))
+			fields := make([]core.Field, 0, min(tf, 1<<16))
+			last := 0
+			for k := uint64(0); k < tf && r.err == nil; k++ {
+				last += int(r.uvarint())
+				positions = append(positions, last)
+				fields = append(fields, core.Field(r.uvarint()))
+			}
+			postings = append(postings, core.Posting{ID: id, TF: len(positions), Positions: positions, Fields: fields})
+			prev = id
+		}
+		idx.Postings[term] = postings
+	}
+	if r.err != nil {
+		return core.Snapshot{}, fmt.Errorf("%w: %v", ErrBadSnapshot, r.err)
+	}
+
+	expected := r.crc
+	var sum [4]byte
+	if _, err := io.ReadFull(in, sum[:]); err != nil {
+		return core.Snapshot{}, fmt.Errorf("%w: %v", ErrBadSnapshot, err)
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != expected {
+		return core.Snapshot{}, fmt.Errorf("%w: checksum mismatch", ErrBadSnapshot)
+	}
+	return core.Snapshot{Index: idx, Watermark: watermark}, nil
+}
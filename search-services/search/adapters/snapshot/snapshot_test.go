@@ -0,0 +1,93 @@
+package snapshot
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s, err := New(log, filepath.Join(t.TempDir(), "index", "snapshot.bin"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	s := newTestStore(t)
+
+	idx := core.BuildIndex(map[int][]core.Token{
+		1:   {{Stem: "foo", Pos: 0, Field: core.FieldTitle}, {Stem: "bar", Pos: 1}, {Stem: "foo", Pos: 2, Field: core.FieldAlt}},
+		7:   tokens("bar"),
+		404: tokens("baz"),
+	})
+	watermark := time.Date(2025, 3, 14, 15, 9, 26, 535, time.UTC)
+
+	if err := s.Save(core.Snapshot{Index: idx, Watermark: watermark}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !got.Watermark.Equal(watermark) {
+		t.Fatalf("expected watermark %v, got %v", watermark, got.Watermark)
+	}
+	if got.Index.Docs() != 3 || got.Index.TotalLen != idx.TotalLen {
+		t.Fatalf("unexpected docs: %v", got.Index.DocLens)
+	}
+	foo := got.Index.Postings["foo"]
+	if len(foo) != 1 || foo[0].ID != 1 || foo[0].TF != 2 ||
+		len(foo[0].Positions) != 2 || foo[0].Positions[0] != 0 || foo[0].Positions[1] != 2 ||
+		foo[0].Fields[0] != core.FieldTitle || foo[0].Fields[1] != core.FieldAlt {
+		t.Fatalf("unexpected foo postings: %v", foo)
+	}
+	bar := got.Index.Postings["bar"]
+	if len(bar) != 2 || bar[0].ID != 1 || bar[1].ID != 7 {
+		t.Fatalf("unexpected bar postings: %v", bar)
+	}
+}
+
+func TestStore_LoadMissing(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.Load(); !errors.Is(err, core.ErrNoSnapshot) {
+		t.Fatalf("expected ErrNoSnapshot, got %v", err)
+	}
+}
+
+func TestStore_LoadCorrupted(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Save(core.Snapshot{Index: core.BuildIndex(map[int][]core.Token{1: tokens("foo")})}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	data[len(data)-6] ^= 0xff
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := s.Load(); !errors.Is(err, ErrBadSnapshot) {
+		t.Fatalf("expected ErrBadSnapshot, got %v", err)
+	}
+}
+
+func tokens(words ...string) []core.Token {
+	out := make([]core.Token, 0, len(words))
+	for i, w := range words {
+		out = append(out, core.Token{Stem: w, Pos: i})
+	}
+	return out
+}
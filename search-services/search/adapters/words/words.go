@@ -2,7 +2,9 @@ package words
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"slices"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -12,40 +14,129 @@ import (
 	"yadro.com/course/search/core"
 )
 
+// maxBatchSize mirrors the batch limit of the words service.
+const maxBatchSize = 1000
+
 type Client struct {
-	log    *slog.Logger
-	client wordspb.WordsClient
+	log     *slog.Logger
+	client  wordspb.WordsClient
+	profile string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+func NewClient(address, profile string, log *slog.Logger) (*Client, error) {
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
-		log:    log,
-		client: wordspb.NewWordsClient(conn),
+		log:     log,
+		client:  wordspb.NewWordsClient(conn),
+		profile: profile,
 	}, nil
 }
 
-func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
+// NormBatch normalizes phrases in one NormBatch call per maxBatchSize of
+// them.
+func (c *Client) NormBatch(ctx context.Context, phrases []string) ([][]core.Token, error) {
+	out := make([][]core.Token, 0, len(phrases))
+	for chunk := range slices.Chunk(phrases, maxBatchSize) {
+		req := &wordspb.BatchRequest{Phrases: make([]*wordspb.WordsRequest, 0, len(chunk))}
+		for _, p := range chunk {
+			req.Phrases = append(req.Phrases, &wordspb.WordsRequest{Phrase: p, Profile: c.profile})
+		}
+		resp, err := c.client.NormBatch(ctx, req)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		for _, reply := range resp.GetReplies() {
+			out = append(out, toTokens(reply))
+		}
+	}
+	return out, nil
+}
+
+func toTokens(reply *wordspb.WordsReply) []core.Token {
+	words, positions := reply.GetWords(), reply.GetPositions()
+	tokens := make([]core.Token, 0, len(words))
+	for i, w := range words {
+		pos := i
+		if i < len(positions) {
+			pos = int(positions[i])
+		}
+		tokens = append(tokens, core.Token{Stem: w, Pos: pos})
+	}
+	return tokens
+}
+
+// AnalyzeBatch returns every word of each text with its byte offsets, stop
+// words included, in one NormBatch call per maxBatchSize texts.
+func (c *Client) AnalyzeBatch(ctx context.Context, texts []string) ([][]core.Word, error) {
+	out := make([][]core.Word, 0, len(texts))
+	for chunk := range slices.Chunk(texts, maxBatchSize) {
+		req := &wordspb.BatchRequest{Phrases: make([]*wordspb.WordsRequest, 0, len(chunk))}
+		for _, text := range chunk {
+			req.Phrases = append(req.Phrases, &wordspb.WordsRequest{Phrase: text, Profile: c.profile})
+		}
+		resp, err := c.client.NormBatch(ctx, req)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		words, err := toWords(resp.GetReplies())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, words...)
+	}
+	return out, nil
+}
+
+func toWords(replies []*wordspb.WordsReply) ([][]core.Word, error) {
+	out := make([][]core.Word, 0, len(replies))
+	for _, reply := range replies {
+		if len(reply.GetTokens()) == 0 && len(reply.GetWords()) > 0 {
+			return nil, errors.New("words service returned no token offsets")
+		}
+		words := make([]core.Word, 0, len(reply.GetTokens()))
+		for _, t := range reply.GetTokens() {
+			words = append(words, core.Word{Stem: t.GetStem(), Start: int(t.GetStart()), End: int(t.GetEnd())})
+		}
+		out = append(out, words)
+	}
+	return out, nil
+}
+
+func (c *Client) Expand(ctx context.Context, phrase string) ([]core.Expansion, error) {
 	if phrase == "" {
 		return nil, core.ErrBadArguments
 	}
 
-	resp, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	resp, err := c.client.Expand(ctx, &wordspb.WordsRequest{Phrase: phrase, Profile: c.profile})
 	if err != nil {
-		if st, ok := status.FromError(err); ok {
-			switch st.Code() {
-			case codes.InvalidArgument:
-				return nil, core.ErrBadArguments
-			case codes.ResourceExhausted:
-				return nil, core.ErrRequestTooLarge
-			default:
-				return nil, err
+		return nil, mapError(err)
+	}
+	out := make([]core.Expansion, 0, len(resp.GetExpansions()))
+	for _, e := range resp.GetExpansions() {
+		terms, positions := e.GetTerms(), e.GetPositions()
+		exp := core.Expansion{Stem: e.GetStem(), Terms: terms, Positions: make([]int, len(terms))}
+		for i := range terms {
+			exp.Positions[i] = i
+			if len(positions) == len(terms) {
+				exp.Positions[i] = int(positions[i])
 			}
 		}
-		return nil, err
+		out = append(out, exp)
+	}
+	return out, nil
+}
+
+func mapError(err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return core.ErrBadArguments
+		case codes.ResourceExhausted:
+			return core.ErrRequestTooLarge
+		}
 	}
-	return resp.GetWords(), nil
+	return err
 }
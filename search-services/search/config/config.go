@@ -8,12 +8,17 @@ import (
 )
 
 type Config struct {
-	LogLevel      string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
-	Address       string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:":8080"`
-	DBAddress     string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
-	WordsAddress  string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:8080"`
-	IndexTTL      time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"24h"`
-	BrokerAddress string        `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"nats://localhost:4222"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
+	Address         string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:":8080"`
+	DBAddress       string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
+	WordsAddress    string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:8080"`
+	IndexTTL        time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"24h"`
+	IndexSnapshot   string        `yaml:"index_snapshot" env:"INDEX_SNAPSHOT" env-default:"index.snapshot"`
+	TitleBoost      float64       `yaml:"title_boost" env:"TITLE_BOOST" env-default:"3"`
+	AltBoost        float64       `yaml:"alt_boost" env:"ALT_BOOST" env-default:"1.5"`
+	TranscriptBoost float64       `yaml:"transcript_boost" env:"TRANSCRIPT_BOOST" env-default:"1"`
+	BrokerAddress   string        `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"nats://localhost:4222"`
+	WordsProfile    string        `yaml:"words_profile" env:"WORDS_PROFILE" env-default:"default"`
 }
 
 func MustLoad(configPath string) Config {
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/fnv"
+)
+
+// cursor is the position of the next page. It is bound to the phrase it was
+// issued for, so it cannot be replayed against another query.
+type cursor struct {
+	Offset int    `json:"o"`
+	Query  uint64 `json:"q"`
+}
+
+func fingerprint(phrase string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(phrase))
+	return h.Sum64()
+}
+
+func encodeCursor(phrase string, offset int) string {
+	data, _ := json.Marshal(cursor{Offset: offset, Query: fingerprint(phrase)})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(phrase, token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrBadCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 || c.Query != fingerprint(phrase) {
+		return 0, ErrBadCursor
+	}
+	return c.Offset, nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	token := encodeCursor("linux cpu", 20)
+	offset, err := decodeCursor("linux cpu", token)
+	if err != nil || offset != 20 {
+		t.Fatalf("expected offset 20, got %d, %v", offset, err)
+	}
+}
+
+func TestCursor_Rejected(t *testing.T) {
+	token := encodeCursor("linux cpu", 20)
+	for _, tc := range []struct{ phrase, token string }{
+		{phrase: "linux", token: token},
+		{phrase: "linux cpu", token: "not a cursor"},
+		{phrase: "linux cpu", token: token[:len(token)-2]},
+	} {
+		if _, err := decodeCursor(tc.phrase, tc.token); !errors.Is(err, ErrBadArguments) {
+			t.Fatalf("expected ErrBadArguments for %q, got %v", tc.token, err)
+		}
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	limit, offset, err := pageOf(SearchParams{Phrase: " linux ", Offset: 5, Cursor: nextCursor("linux", 10, 10, 42)})
+	if err != nil || limit != defaultLimit || offset != 20 {
+		t.Fatalf("unexpected page: limit %d, offset %d, %v", limit, offset, err)
+	}
+	if _, _, err := pageOf(SearchParams{Phrase: "linux", Offset: -1}); !errors.Is(err, ErrBadArguments) {
+		t.Fatalf("expected ErrBadArguments, got %v", err)
+	}
+	if next := nextCursor("linux", 40, 2, 42); next != "" {
+		t.Fatalf("expected no cursor on the last page, got %q", next)
+	}
+
+	deep := encodeCursor("linux", maxOffset+1)
+	for _, params := range []SearchParams{
+		{Phrase: "linux", Offset: maxOffset + 1},
+		{Phrase: "linux", Cursor: deep},
+	} {
+		if _, _, err := pageOf(params); !errors.Is(err, ErrBadArguments) {
+			t.Fatalf("expected ErrBadArguments past the last page, got %v", err)
+		}
+	}
+	if next := nextCursor("linux", maxOffset, 10, 2*maxOffset); next != "" {
+		t.Fatalf("expected no cursor past the last page, got %q", next)
+	}
+}
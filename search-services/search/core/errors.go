@@ -1,9 +1,15 @@
 package core
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrBadArguments    = errors.New("arguments are not acceptable")
 	ErrNilDependency   = errors.New("search service: nil dependency")
 	ErrRequestTooLarge = errors.New("request is too large")
+	ErrNoSnapshot      = errors.New("index snapshot is not available")
+	ErrNotFound        = errors.New("resource is not found")
+	ErrBadCursor       = fmt.Errorf("%w: malformed or foreign cursor", ErrBadArguments)
 )
@@ -0,0 +1,170 @@
+package core
+
+import "sort"
+
+const maxFuzzyVariants = 5
+
+// Variant is an index term standing in for a query stem, weighted below 1 so
+// that it never outranks the stem itself.
+type Variant struct {
+	Term   string
+	Weight float64
+}
+
+// WeightedTerm is a stem together with the share of its score it contributes.
+type WeightedTerm struct {
+	Term   string
+	Weight float64
+}
+
+// maxEdits bounds the edit distance allowed for a stem: short stems are too
+// ambiguous to be corrected at all.
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func fuzzyWeight(dist int) float64 {
+	return 1 / float64(1+dist)
+}
+
+// bkTree indexes terms by Levenshtein distance so that all terms within a
+// distance of a query can be found without scanning the whole vocabulary.
+type bkTree struct {
+	root *bkNode
+}
+
+type bkNode struct {
+	term     string
+	children map[int]*bkNode
+}
+
+func newBKTree(terms []string) *bkTree {
+	t := &bkTree{}
+	for _, term := range terms {
+		t.insert(term)
+	}
+	return t
+}
+
+func (t *bkTree) insert(term string) {
+	if t.root == nil {
+		t.root = &bkNode{term: term}
+		return
+	}
+	node := t.root
+	for {
+		d := levenshtein(term, node.term)
+		if d == 0 {
+			return
+		}
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+			node.children[d] = &bkNode{term: term}
+			return
+		}
+		node = child
+	}
+}
+
+type fuzzyMatch struct {
+	term string
+	dist int
+}
+
+func (t *bkTree) search(term string, maxDist int) []fuzzyMatch {
+	if t.root == nil {
+		return nil
+	}
+	var out []fuzzyMatch
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := levenshtein(term, node.term)
+		if d <= maxDist {
+			out = append(out, fuzzyMatch{term: node.term, dist: d})
+		}
+		for cd, child := range node.children {
+			if cd >= d-maxDist && cd <= d+maxDist {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return out
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Similar returns up to maxFuzzyVariants index terms within the allowed edit
+// distance of term, closest and most frequent first, the term itself excluded.
+func (idx *Index) Similar(term string) []Variant {
+	maxDist := maxEdits(term)
+	if maxDist == 0 {
+		return nil
+	}
+
+	idx.treeMu.Lock()
+	if idx.tree == nil {
+		terms := make([]string, 0, len(idx.Postings))
+		for t := range idx.Postings {
+			terms = append(terms, t)
+		}
+		sort.Strings(terms)
+		idx.tree = newBKTree(terms)
+	}
+	tree := idx.tree
+	idx.treeMu.Unlock()
+
+	matches := tree.search(term, maxDist)
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		di, dj := len(idx.Postings[matches[i].term]), len(idx.Postings[matches[j].term])
+		if di != dj {
+			return di > dj
+		}
+		return matches[i].term < matches[j].term
+	})
+
+	var out []Variant
+	for _, m := range matches {
+		if m.dist == 0 {
+			continue
+		}
+		out = append(out, Variant{Term: m.term, Weight: fuzzyWeight(m.dist)})
+		if len(out) == maxFuzzyVariants {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "python", b: "pythn", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "", b: "abc", want: 3},
+		{a: "хакер", b: "хакеры", want: 1},
+	}
+	for _, tc := range cases {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Fatalf("levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestBKTreeSearch(t *testing.T) {
+	terms := []string{"python", "pythons", "typhoon", "java", "pylon", "photon"}
+	tree := newBKTree(terms)
+
+	got := tree.search("pythn", 2)
+	var found []string
+	for _, m := range got {
+		found = append(found, m.term)
+		if want := levenshtein("pythn", m.term); m.dist != want {
+			t.Fatalf("wrong distance for %q: %d", m.term, m.dist)
+		}
+	}
+	sort.Strings(found)
+
+	var want []string
+	for _, term := range terms {
+		if levenshtein("pythn", term) <= 2 {
+			want = append(want, term)
+		}
+	}
+	sort.Strings(want)
+	if len(found) != len(want) {
+		t.Fatalf("expected %v, got %v", want, found)
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, found)
+		}
+	}
+}
+
+func TestIndexFuzzy_ExactOutranksVariant(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("python", "snake"),
+		2: tokens("pythn", "snake"),
+		3: tokens("java"),
+	})
+
+	q := &Query{Op: QueryTerm, Text: "pythn", Terms: []string{"pythn"}}
+	q.Variants = [][]Variant{idx.Similar("pythn")}
+	if len(q.Variants[0]) != 1 || q.Variants[0][0].Term != "python" {
+		t.Fatalf("unexpected variants: %v", q.Variants)
+	}
+
+	matched := idx.Match(q)
+	if len(matched) != 2 {
+		t.Fatalf("expected 2 matches, got %v", matched)
+	}
+	scores := idx.ScoreWeighted(q.WeightedTerms(), FieldBoosts{})
+	if scores[2] <= scores[1] {
+		t.Fatalf("expected exact hit to outrank fuzzy hit, got %v", scores)
+	}
+
+	idx.Merge(map[int][]Token{4: tokens("pythin")})
+	if got := idx.Similar("pythn"); len(got) != 2 {
+		t.Fatalf("expected vocabulary to refresh after merge, got %v", got)
+	}
+}
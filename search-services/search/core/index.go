@@ -0,0 +1,385 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Posting keeps the sorted positions of a term within a document along with
+// the field of every position; TF is their count.
+type Posting struct {
+	ID        int
+	TF        int
+	Positions []int
+	Fields    []Field
+}
+
+// in returns the positions that fall into the given fields, all of them when
+// no fields are given.
+func (p Posting) in(fields []Field) []int {
+	if len(fields) == 0 {
+		return p.Positions
+	}
+	var out []int
+	for i, pos := range p.Positions {
+		if slices.Contains(fields, p.Fields[i]) {
+			out = append(out, pos)
+		}
+	}
+	return out
+}
+
+type Index struct {
+	Postings map[string][]Posting
+	DocLens  map[int]int
+	TotalLen int
+
+	treeMu sync.Mutex
+	tree   *bkTree
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Postings: make(map[string][]Posting),
+		DocLens:  make(map[int]int),
+	}
+}
+
+func BuildIndex(data map[int][]Token) *Index {
+	idx := NewIndex()
+	for id, tokens := range data {
+		idx.add(id, tokens)
+	}
+	idx.sortPostings()
+	return idx
+}
+
+// Merge replaces the documents with the given ids; ids with no tokens are
+// removed. It returns the words whose postings changed.
+func (idx *Index) Merge(data map[int][]Token) map[string]struct{} {
+	ids := make([]int, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	changed := idx.Remove(ids)
+	idx.tree = nil
+
+	touched := make(map[string]struct{})
+	for id, tokens := range data {
+		for word := range idx.add(id, tokens) {
+			touched[word] = struct{}{}
+		}
+	}
+	for word := range touched {
+		sortPostings(idx.Postings[word])
+		changed[word] = struct{}{}
+	}
+	return changed
+}
+
+// Remove drops the documents with the given ids and returns the words whose
+// postings changed.
+func (idx *Index) Remove(ids []int) map[string]struct{} {
+	changed := make(map[string]struct{})
+	drop := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if length, ok := idx.DocLens[id]; ok {
+			drop[id] = struct{}{}
+			idx.TotalLen -= length
+			delete(idx.DocLens, id)
+		}
+	}
+	if len(drop) == 0 {
+		return changed
+	}
+	idx.tree = nil
+	for word, postings := range idx.Postings {
+		kept := postings[:0]
+		for _, p := range postings {
+			if _, ok := drop[p.ID]; !ok {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) < len(postings) {
+			changed[word] = struct{}{}
+		}
+		if len(kept) == 0 {
+			delete(idx.Postings, word)
+			continue
+		}
+		idx.Postings[word] = kept
+	}
+	return changed
+}
+
+func (idx *Index) Has(id int) bool {
+	_, ok := idx.DocLens[id]
+	return ok
+}
+
+func (idx *Index) add(id int, tokens []Token) map[string]struct{} {
+	sorted := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Stem != "" {
+			sorted = append(sorted, t)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Pos < sorted[j].Pos })
+
+	postings := make(map[string]*Posting)
+	for _, t := range sorted {
+		p, ok := postings[t.Stem]
+		if !ok {
+			p = &Posting{ID: id}
+			postings[t.Stem] = p
+		}
+		p.TF++
+		p.Positions = append(p.Positions, t.Pos)
+		p.Fields = append(p.Fields, t.Field)
+	}
+	touched := make(map[string]struct{}, len(postings))
+	for word, p := range postings {
+		idx.Postings[word] = append(idx.Postings[word], *p)
+		touched[word] = struct{}{}
+	}
+	idx.DocLens[id] = len(sorted)
+	idx.TotalLen += len(sorted)
+	return touched
+}
+
+func (idx *Index) sortPostings() {
+	for _, postings := range idx.Postings {
+		sortPostings(postings)
+	}
+}
+
+func sortPostings(postings []Posting) {
+	sort.Slice(postings, func(i, j int) bool { return postings[i].ID < postings[j].ID })
+}
+
+func (idx *Index) Docs() int {
+	return len(idx.DocLens)
+}
+
+func (idx *Index) avgDocLen() float64 {
+	if len(idx.DocLens) == 0 {
+		return 0
+	}
+	return float64(idx.TotalLen) / float64(len(idx.DocLens))
+}
+
+func (idx *Index) idf(df int) float64 {
+	n := float64(idx.Docs())
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// Score returns BM25 scores of every document containing at least one of the
+// words. Every occurrence adds the boost of its field to the term frequency.
+func (idx *Index) Score(words []string, boosts FieldBoosts) map[int]float64 {
+	terms := make([]WeightedTerm, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, WeightedTerm{Term: word, Weight: 1})
+	}
+	return idx.ScoreWeighted(terms, boosts)
+}
+
+// ScoreWeighted is Score with the contribution of every term scaled by its
+// weight.
+func (idx *Index) ScoreWeighted(terms []WeightedTerm, boosts FieldBoosts) map[int]float64 {
+	scores := make(map[int]float64)
+	avg := idx.avgDocLen()
+	if avg == 0 {
+		return scores
+	}
+	for _, term := range terms {
+		postings := idx.Postings[term.Term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := idx.idf(len(postings))
+		for _, p := range postings {
+			tf := 0.0
+			for _, f := range p.Fields {
+				tf += boosts.Boost(f)
+			}
+			norm := 1 - bm25B + bm25B*float64(idx.DocLens[p.ID])/avg
+			scores[p.ID] += term.Weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// Match returns the ids of documents satisfying the query.
+func (idx *Index) Match(q *Query) map[int]struct{} {
+	switch q.Op {
+	case QueryTerm:
+		out := make(map[int]struct{})
+		for i := range q.Terms {
+			for _, term := range q.alternatives(i) {
+				for _, p := range idx.Postings[term] {
+					if len(p.in(q.Fields)) > 0 {
+						out[p.ID] = struct{}{}
+					}
+				}
+			}
+		}
+		return out
+	case QueryPhrase:
+		return idx.matchPhrase(q)
+	case QueryNot:
+		excluded := idx.Match(q.Children[0])
+		out := make(map[int]struct{}, len(idx.DocLens))
+		for id := range idx.DocLens {
+			if _, ok := excluded[id]; !ok {
+				out[id] = struct{}{}
+			}
+		}
+		return out
+	case QueryAnd:
+		var out map[int]struct{}
+		for _, c := range q.Children {
+			if c.Op == QueryNot && out != nil {
+				for id := range idx.Match(c.Children[0]) {
+					delete(out, id)
+				}
+				continue
+			}
+			matched := idx.Match(c)
+			if out == nil {
+				out = matched
+				continue
+			}
+			for id := range out {
+				if _, ok := matched[id]; !ok {
+					delete(out, id)
+				}
+			}
+		}
+		return out
+	case QueryOr:
+		out := make(map[int]struct{})
+		for _, c := range q.Children {
+			for id := range idx.Match(c) {
+				out[id] = struct{}{}
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// matchAll returns the documents holding every term of the leaf or one of
+// its variants.
+func (idx *Index) matchAll(q *Query) map[int]struct{} {
+	var out map[int]struct{}
+	for i := range q.Terms {
+		next := make(map[int]struct{})
+		for _, term := range q.alternatives(i) {
+			for _, p := range idx.Postings[term] {
+				if _, ok := out[p.ID]; ok || out == nil {
+					next[p.ID] = struct{}{}
+				}
+			}
+		}
+		out = next
+	}
+	if out == nil {
+		out = make(map[int]struct{})
+	}
+	return out
+}
+
+// matchPhrase returns the documents where the phrase terms occur at their
+// query offsets or, with a positive slop, within a window that much wider
+// than the phrase itself.
+func (idx *Index) matchPhrase(q *Query) map[int]struct{} {
+	candidates := idx.matchAll(q)
+	phrase := len(q.Terms) > 1 && len(q.Offsets) == len(q.Terms)
+	if !phrase && len(q.Fields) == 0 {
+		return candidates
+	}
+	out := make(map[int]struct{}, len(candidates))
+	for id := range candidates {
+		positions := make([][]int, len(q.Terms))
+		ok := true
+		for i := range q.Terms {
+			for _, term := range q.alternatives(i) {
+				positions[i] = append(positions[i], idx.posting(term, id).in(q.Fields)...)
+			}
+			if len(q.Variants) > 0 {
+				sort.Ints(positions[i])
+			}
+			ok = ok && len(positions[i]) > 0
+		}
+		switch {
+		case !ok || !phrase:
+		case q.Slop == 0:
+			ok = exactPhrase(positions, q.Offsets)
+		default:
+			span := q.Offsets[len(q.Offsets)-1] - q.Offsets[0]
+			ok = minWindow(positions) <= span+q.Slop
+		}
+		if ok {
+			out[id] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (idx *Index) posting(term string, id int) Posting {
+	postings := idx.Postings[term]
+	i := sort.Search(len(postings), func(i int) bool { return postings[i].ID >= id })
+	if i < len(postings) && postings[i].ID == id {
+		return postings[i]
+	}
+	return Posting{}
+}
+
+func exactPhrase(positions [][]int, offsets []int) bool {
+	for _, start := range positions[0] {
+		matched := true
+		for i := 1; i < len(positions) && matched; i++ {
+			want := start + offsets[i] - offsets[0]
+			j := sort.SearchInts(positions[i], want)
+			matched = j < len(positions[i]) && positions[i][j] == want
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// minWindow returns the smallest distance between the first and the last
+// position of a window holding one position of every list.
+func minWindow(positions [][]int) int {
+	cursor := make([]int, len(positions))
+	best := math.MaxInt
+	for {
+		lo, hi, loList := math.MaxInt, math.MinInt, -1
+		for i, pos := range positions {
+			if cursor[i] >= len(pos) {
+				return best
+			}
+			p := pos[cursor[i]]
+			if p < lo {
+				lo, loList = p, i
+			}
+			if p > hi {
+				hi = p
+			}
+		}
+		best = min(best, hi-lo)
+		cursor[loList]++
+	}
+}
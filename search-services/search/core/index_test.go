@@ -0,0 +1,88 @@
+package core
+
+import "testing"
+
+func TestIndexScore_TermFrequency(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("physic", "cat", "dog", "bird"),
+		2: tokens("physic", "physic", "physic", "cat"),
+		3: tokens("cat", "dog"),
+	})
+
+	scores := idx.Score([]string{"physic"}, FieldBoosts{})
+	if len(scores) != 2 {
+		t.Fatalf("expected 2 scored docs, got %d", len(scores))
+	}
+	if scores[2] <= scores[1] {
+		t.Fatalf("expected doc 2 to outrank doc 1, got %v", scores)
+	}
+	if _, ok := scores[3]; ok {
+		t.Fatalf("doc 3 must not match")
+	}
+}
+
+func TestIndexScore_RareTermsWeighMore(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("cat", "quantum"),
+		2: tokens("cat", "dog"),
+		3: tokens("cat", "dog"),
+	})
+
+	scores := idx.Score([]string{"cat", "quantum", "dog"}, FieldBoosts{})
+	if scores[1] <= scores[2] {
+		t.Fatalf("expected rare term to dominate, got %v", scores)
+	}
+}
+
+func TestIndexScore_FieldBoosts(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: {{Stem: "cat", Pos: 0, Field: FieldTitle}, {Stem: "dog", Pos: 1000, Field: FieldTranscript}},
+		2: {{Stem: "dog", Pos: 0, Field: FieldTitle}, {Stem: "cat", Pos: 1000, Field: FieldTranscript}},
+	})
+
+	scores := idx.Score([]string{"cat"}, FieldBoosts{Title: 3, Alt: 1.5, Transcript: 1})
+	if scores[1] <= scores[2] {
+		t.Fatalf("expected title hit to outrank transcript hit, got %v", scores)
+	}
+}
+
+func TestIndexScore_Empty(t *testing.T) {
+	idx := NewIndex()
+	if scores := idx.Score([]string{"cat"}, FieldBoosts{}); len(scores) != 0 {
+		t.Fatalf("expected no scores, got %v", scores)
+	}
+}
+
+func TestIndexMerge_ReplacesAndRemoves(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("cat", "dog"),
+		2: tokens("dog"),
+	})
+
+	idx.Merge(map[int][]Token{
+		1: tokens("bird"),
+		2: nil,
+		3: tokens("cat", "cat"),
+	})
+
+	if idx.Docs() != 2 || idx.Has(2) {
+		t.Fatalf("unexpected docs after merge: %v", idx.DocLens)
+	}
+	if _, ok := idx.Postings["dog"]; ok {
+		t.Fatalf("stale term must be dropped")
+	}
+	if got := idx.Postings["cat"]; len(got) != 1 || got[0].ID != 3 || got[0].TF != 2 {
+		t.Fatalf("unexpected cat postings: %v", got)
+	}
+	if idx.TotalLen != 3 {
+		t.Fatalf("expected total length 3, got %d", idx.TotalLen)
+	}
+}
+
+func tokens(words ...string) []Token {
+	out := make([]Token, 0, len(words))
+	for i, w := range words {
+		out = append(out, Token{Stem: w, Pos: i})
+	}
+	return out
+}
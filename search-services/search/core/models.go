@@ -1,18 +1,121 @@
 package core
 
+import (
+	"strings"
+	"time"
+)
+
 type Comic struct {
-	ID  int
-	URL string
+	ID           int
+	URL          string
+	Title        string
+	Snippet      string
+	MatchedTerms []string
+	Score        float64
+}
+
+// ComicText is what search results are described with: the texts to cut a
+// snippet from and the stems the comic was indexed with. Completions look
+// up the words of stems in the texts too.
+type ComicText struct {
+	Title      string
+	Alt        string
+	Transcript string
+	Words      []string
+}
+
+type Field int
+
+const (
+	FieldUnknown Field = iota
+	FieldTitle
+	FieldAlt
+	FieldTranscript
+)
+
+var fieldNames = map[string]Field{
+	"title":      FieldTitle,
+	"alt":        FieldAlt,
+	"transcript": FieldTranscript,
+}
+
+func ParseField(name string) (Field, bool) {
+	f, ok := fieldNames[strings.ToLower(name)]
+	return f, ok
+}
+
+// FieldBoosts weighs term occurrences by the field they come from. Tokens of
+// an unknown field always weigh 1.
+type FieldBoosts struct {
+	Title      float64
+	Alt        float64
+	Transcript float64
 }
 
-type Index map[string][]int
+func (b FieldBoosts) Boost(f Field) float64 {
+	switch f {
+	case FieldTitle:
+		return b.Title
+	case FieldAlt:
+		return b.Alt
+	case FieldTranscript:
+		return b.Transcript
+	}
+	return 1
+}
+
+type Token struct {
+	Stem  string
+	Pos   int
+	Field Field
+}
 
+// Word is a word of a text with its byte offsets in the text. Stem is empty
+// for words the words service dropped, like stop words.
+type Word struct {
+	Stem  string
+	Start int
+	End   int
+}
+
+// Expansion is a synonym or a longer form of a query stem, given as stems
+// with their relative word positions.
+type Expansion struct {
+	Stem      string
+	Terms     []string
+	Positions []int
+}
+
+type ComicInfo struct {
+	ID         int
+	URL        string
+	Title      string
+	Alt        string
+	Transcript string
+	Published  time.Time
+	PageURL    string
+	FetchedAt  time.Time
+}
+
+// SearchParams select a page either by Offset or by the Cursor returned with
+// the previous page; the cursor wins when both are set. Fuzzy lets ISearch
+// match index terms a few edits away from the query stems.
 type SearchParams struct {
 	Phrase string
 	Limit  int
+	Offset int
+	Cursor string
+	Fuzzy  bool
 }
 
 type SearchResult struct {
-	Comics []Comic
-	Total  int
+	Comics      []Comic
+	Total       int
+	NextCursor  string
+	Suggestions []string
+}
+
+type Snapshot struct {
+	Index     *Index
+	Watermark time.Time
 }
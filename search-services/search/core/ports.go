@@ -1,19 +1,43 @@
 package core
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Searcher interface {
 	Search(ctx context.Context, params SearchParams) (SearchResult, error)
 	ISearch(ctx context.Context, params SearchParams) (SearchResult, error)
+	GetComic(ctx context.Context, id int) (ComicInfo, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Completion, error)
 	RebuildIndex(ctx context.Context) error
+	UpdateIndex(ctx context.Context, ids []int) error
+	SyncIndex(ctx context.Context) error
+	RestoreIndex(ctx context.Context) error
+	SaveIndex(ctx context.Context) error
 }
 
 type Storage interface {
-	SearchComics(ctx context.Context, words []string, limit int) ([]Comic, int, error)
-	LoadIndexData(ctx context.Context) (map[int][]string, error)
+	SearchComics(ctx context.Context, q *Query, limit, offset int) ([]Comic, int, error)
+	LoadIndexData(ctx context.Context, since time.Time) (map[int][]Token, time.Time, error)
+	LoadIndexDataByIDs(ctx context.Context, ids []int) (map[int][]Token, error)
+	IDs(ctx context.Context) ([]int, error)
 	GetComicsByIDs(ctx context.Context, ids []int) ([]Comic, error)
+	GetComic(ctx context.Context, id int) (ComicInfo, error)
+	GetComicTexts(ctx context.Context, ids []int) (map[int]ComicText, error)
 }
 
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	// NormBatch returns the stems of each phrase with their word positions,
+	// in the order of the phrases.
+	NormBatch(ctx context.Context, phrases []string) ([][]Token, error)
+	// AnalyzeBatch returns every word of each text with its byte offsets,
+	// stop words included, in the order of the texts.
+	AnalyzeBatch(ctx context.Context, texts []string) ([][]Word, error)
+	Expand(ctx context.Context, phrase string) ([]Expansion, error)
+}
+
+type SnapshotStore interface {
+	Save(snapshot Snapshot) error
+	Load() (Snapshot, error)
 }
@@ -0,0 +1,541 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type QueryOp int
+
+const maxSlop = 100
+
+const (
+	QueryTerm QueryOp = iota
+	QueryPhrase
+	QueryAnd
+	QueryOr
+	QueryNot
+)
+
+// Query is a node of a parsed search query. Leaves (terms and phrases) keep
+// the raw text and, once normalized, the stems it produced. Phrases also keep
+// the relative position of every stem and the allowed proximity slop. Leaves
+// restricted to some fields list them in Fields. Variants, when set, runs
+// parallel to Terms and lists index terms accepted in place of each stem.
+// Weight scales the score of leaves added by query expansion; zero means 1.
+type Query struct {
+	Op       QueryOp
+	Text     string
+	Terms    []string
+	Variants [][]Variant
+	Offsets  []int
+	Slop     int
+	Fields   []Field
+	Children []*Query
+	Pos      int
+	Weight   float64
+}
+
+// alternatives returns the i-th stem of a leaf followed by its variants.
+func (q *Query) alternatives(i int) []string {
+	terms := []string{q.Terms[i]}
+	if i < len(q.Variants) {
+		for _, v := range q.Variants[i] {
+			terms = append(terms, v.Term)
+		}
+	}
+	return terms
+}
+
+// TermsWithVariants returns every stem of a leaf followed by its variants.
+func (q *Query) TermsWithVariants() []string {
+	var terms []string
+	for i := range q.Terms {
+		terms = append(terms, q.alternatives(i)...)
+	}
+	return terms
+}
+
+// addVariant accepts v in place of the i-th stem unless it is already accepted.
+func (q *Query) addVariant(i int, v Variant) {
+	for _, term := range q.alternatives(i) {
+		if term == v.Term {
+			return
+		}
+	}
+	for len(q.Variants) < len(q.Terms) {
+		q.Variants = append(q.Variants, nil)
+	}
+	q.Variants[i] = append(q.Variants[i], v)
+}
+
+func (q *Query) weight() float64 {
+	if q.Weight == 0 {
+		return 1
+	}
+	return q.Weight
+}
+
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrBadArguments
+}
+
+// PositiveTerms returns the stems that contribute to matching, skipping
+// everything under a negation.
+func (q *Query) PositiveTerms() []string {
+	var terms []string
+	for _, leaf := range q.PositiveLeaves() {
+		terms = append(terms, leaf.Terms...)
+	}
+	return deduplicateWords(terms)
+}
+
+// WeightedTerms returns the positive stems with the weight of their leaf and
+// their variants with their own weights scaled by it, every term once with
+// its highest weight.
+func (q *Query) WeightedTerms() []WeightedTerm {
+	weights := make(map[string]float64)
+	var order []string
+	add := func(term string, weight float64) {
+		w, ok := weights[term]
+		if !ok {
+			order = append(order, term)
+		}
+		if weight > w {
+			weights[term] = weight
+		}
+	}
+	for _, leaf := range q.PositiveLeaves() {
+		w := leaf.weight()
+		for i, term := range leaf.Terms {
+			add(term, w)
+			if i < len(leaf.Variants) {
+				for _, v := range leaf.Variants[i] {
+					add(v.Term, v.Weight*w)
+				}
+			}
+		}
+	}
+	out := make([]WeightedTerm, 0, len(order))
+	for _, term := range order {
+		out = append(out, WeightedTerm{Term: term, Weight: weights[term]})
+	}
+	return out
+}
+
+// PositiveLeaves returns the terms and phrases outside of any negation.
+func (q *Query) PositiveLeaves() []*Query {
+	var leaves []*Query
+	var walk func(n *Query)
+	walk = func(n *Query) {
+		switch n.Op {
+		case QueryTerm, QueryPhrase:
+			leaves = append(leaves, n)
+		case QueryNot:
+		default:
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(q)
+	return leaves
+}
+
+// hasPhrases reports whether q has a phrase of several words anywhere.
+func (q *Query) hasPhrases() bool {
+	if q.Op == QueryPhrase {
+		return len(q.Terms) > 1
+	}
+	for _, c := range q.Children {
+		if c.hasPhrases() {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokAnd
+	tokOr
+	tokNot
+	tokMinus
+	tokLParen
+	tokRParen
+	tokField
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	slop  int
+	field Field
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokPhrase:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+func isSpecial(r rune) bool {
+	return r == '(' || r == ')' || r == '"'
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	i := 0
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		pos++
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: pos})
+			i += size
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: pos})
+			i += size
+		case r == '"':
+			start := pos
+			end := strings.IndexRune(input[i+size:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{Pos: start, Msg: "unterminated quoted phrase"}
+			}
+			text := input[i+size : i+size+end]
+			pos += utf8.RuneCountInString(text) + 1
+			i += size + end + 1
+			slop := 0
+			if i < len(input) && input[i] == '~' {
+				j := i + 1
+				for j < len(input) && input[j] >= '0' && input[j] <= '9' {
+					j++
+				}
+				n, err := strconv.Atoi(input[i+1 : j])
+				if err != nil || n > maxSlop {
+					return nil, &SyntaxError{Pos: pos + 1, Msg: fmt.Sprintf("expected proximity between 0 and %d after '~'", maxSlop)}
+				}
+				slop = n
+				pos += j - i
+				i = j
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text, pos: start, slop: slop})
+		default:
+			j := i
+			for j < len(input) {
+				r2, size2 := utf8.DecodeRuneInString(input[j:])
+				if unicode.IsSpace(r2) || isSpecial(r2) {
+					break
+				}
+				j += size2
+			}
+			word := input[i:j]
+			if strings.HasPrefix(word, "-") && len(word) > 1 {
+				tokens = append(tokens, token{kind: tokMinus, text: "-", pos: pos})
+				word = word[1:]
+				pos++
+			} else if word == "-" && j < len(input) && (input[j] == '(' || input[j] == '"') {
+				tokens = append(tokens, token{kind: tokMinus, text: "-", pos: pos})
+				i = j
+				continue
+			}
+			if k := strings.IndexByte(word, ':'); k > 0 {
+				if field, ok := ParseField(word[:k]); ok {
+					tokens = append(tokens, token{kind: tokField, text: word[:k+1], pos: pos, field: field})
+					pos += k + 1
+					word = word[k+1:]
+					if word == "" {
+						pos--
+						i = j
+						continue
+					}
+				}
+			}
+			kind := tokWord
+			switch word {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: pos})
+			pos += utf8.RuneCountInString(word) - 1
+			i = j
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: pos + 1})
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	cur    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.cur]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.cur]
+	if t.kind != tokEOF {
+		p.cur++
+	}
+	return t
+}
+
+// ParseQuery parses the query grammar:
+//
+//	or      = and { ["OR"] and }
+//	and     = unary { "AND" unary }
+//	unary   = ("NOT" | "-") unary | primary
+//	primary = [ field ":" ] ( word | '"' phrase '"' [ "~" slop ] | "(" or ")" )
+//
+// Adjacent clauses are OR-ed. A negated clause joined to its group by
+// adjacency, like "-b" in "a -b", excludes matches from the whole group; one
+// joined by an explicit OR, like "a OR NOT b", stays a complement OR-ed with
+// the rest.
+// A quoted phrase requires its words to be adjacent; "~N" lets them appear in
+// any order within N extra positions. A "title:", "alt:" or "transcript:"
+// prefix restricts matching to that field.
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: "unexpected " + t.String()}
+	}
+	return q, nil
+}
+
+func startsClause(t token) bool {
+	switch t.kind {
+	case tokWord, tokPhrase, tokLParen, tokNot, tokMinus, tokField:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (*Query, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	clauses := []*Query{first}
+	// explicit[i] tells whether clause i is joined to the group by OR: the
+	// first clause by the joint after it, the others by the one before.
+	explicit := []bool{false}
+	for {
+		t := p.peek()
+		or := t.kind == tokOr
+		if or {
+			p.next()
+			if !startsClause(p.peek()) {
+				return nil, &SyntaxError{Pos: p.peek().pos, Msg: "expected term after OR"}
+			}
+		} else if !startsClause(t) {
+			break
+		}
+		q, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if len(clauses) == 1 {
+			explicit[0] = or
+		}
+		clauses = append(clauses, q)
+		explicit = append(explicit, or)
+	}
+	if len(clauses) == 1 {
+		return first, nil
+	}
+
+	var children, excluded []*Query
+	for i, c := range clauses {
+		if c.Op == QueryNot && !explicit[i] {
+			excluded = append(excluded, c)
+			continue
+		}
+		children = append(children, c)
+	}
+	var group *Query
+	switch len(children) {
+	case 0:
+	case 1:
+		group = children[0]
+	default:
+		group = &Query{Op: QueryOr, Children: children, Pos: first.Pos}
+	}
+	if len(excluded) == 0 {
+		return group, nil
+	}
+	and := &Query{Op: QueryAnd, Pos: first.Pos}
+	if group != nil {
+		and.Children = append(and.Children, group)
+	}
+	and.Children = append(and.Children, excluded...)
+	return and, nil
+}
+
+func (p *parser) parseAnd() (*Query, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Query{first}
+	for p.peek().kind == tokAnd {
+		p.next()
+		if !startsClause(p.peek()) {
+			return nil, &SyntaxError{Pos: p.peek().pos, Msg: "expected term after AND"}
+		}
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, q)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &Query{Op: QueryAnd, Children: children, Pos: first.Pos}, nil
+}
+
+func (p *parser) parseUnary() (*Query, error) {
+	t := p.peek()
+	if t.kind == tokNot || t.kind == tokMinus {
+		p.next()
+		if !startsClause(p.peek()) {
+			return nil, &SyntaxError{Pos: p.peek().pos, Msg: "expected term after " + t.String()}
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Query{Op: QueryNot, Children: []*Query{child}, Pos: t.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (*Query, error) {
+	t := p.next()
+	switch t.kind {
+	case tokField:
+		next := p.peek()
+		if next.kind != tokWord && next.kind != tokPhrase && next.kind != tokLParen {
+			return nil, &SyntaxError{Pos: next.pos, Msg: "expected term after " + t.String()}
+		}
+		q, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		restrict(q, t.field)
+		return q, nil
+	case tokWord:
+		return &Query{Op: QueryTerm, Text: t.text, Pos: t.pos}, nil
+	case tokPhrase:
+		return &Query{Op: QueryPhrase, Text: t.text, Slop: t.slop, Pos: t.pos}, nil
+	case tokLParen:
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &SyntaxError{Pos: t.pos, Msg: "missing closing parenthesis"}
+		}
+		p.next()
+		return q, nil
+	default:
+		return nil, &SyntaxError{Pos: t.pos, Msg: "unexpected " + t.String()}
+	}
+}
+
+// restrict limits every leaf of q to the field unless a nested prefix already
+// picked one.
+func restrict(q *Query, field Field) {
+	if q.Op == QueryTerm || q.Op == QueryPhrase {
+		if len(q.Fields) == 0 {
+			q.Fields = []Field{field}
+		}
+		return
+	}
+	for _, c := range q.Children {
+		restrict(c, field)
+	}
+}
+
+// Simplify drops leaves without terms and flattens nested groups.
+func (q *Query) Simplify() *Query {
+	if q == nil {
+		return nil
+	}
+	switch q.Op {
+	case QueryTerm, QueryPhrase:
+		if len(q.Terms) == 0 {
+			return nil
+		}
+		return q
+	case QueryNot:
+		child := q.Children[0].Simplify()
+		if child == nil {
+			return nil
+		}
+		if child.Op == QueryNot {
+			return child.Children[0]
+		}
+		return &Query{Op: QueryNot, Children: []*Query{child}, Pos: q.Pos}
+	}
+
+	var children []*Query
+	for _, c := range q.Children {
+		c = c.Simplify()
+		if c == nil {
+			continue
+		}
+		if c.Op == q.Op {
+			children = append(children, c.Children...)
+			continue
+		}
+		children = append(children, c)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &Query{Op: q.Op, Children: children, Pos: q.Pos}
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func render(q *Query) string {
+	if q == nil {
+		return "<nil>"
+	}
+	switch q.Op {
+	case QueryTerm:
+		return prefix(q) + q.Text
+	case QueryPhrase:
+		if q.Slop > 0 {
+			return prefix(q) + `"` + q.Text + `"~` + strconv.Itoa(q.Slop)
+		}
+		return prefix(q) + `"` + q.Text + `"`
+	case QueryNot:
+		return "NOT " + render(q.Children[0])
+	}
+	sep := " AND "
+	if q.Op == QueryOr {
+		sep = " OR "
+	}
+	parts := make([]string, 0, len(q.Children))
+	for _, c := range q.Children {
+		parts = append(parts, render(c))
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+func prefix(q *Query) string {
+	var out string
+	for _, f := range q.Fields {
+		for name, field := range fieldNames {
+			if field == f {
+				out += name + ":"
+			}
+		}
+	}
+	return out
+}
+
+func fillTerms(q *Query) {
+	if q.Op == QueryTerm || q.Op == QueryPhrase {
+		q.Terms = strings.Fields(strings.ToLower(q.Text))
+		q.Offsets = make([]int, len(q.Terms))
+		for i := range q.Offsets {
+			q.Offsets[i] = i
+		}
+		return
+	}
+	for _, c := range q.Children {
+		fillTerms(c)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{input: "linux cpu", want: "(linux OR cpu)"},
+		{input: "linux AND cpu OR video", want: "((linux AND cpu) OR video)"},
+		{input: `"little bobby" -tables`, want: `("little bobby" AND NOT tables)`},
+		{input: "a (b OR c) NOT d", want: "((a OR b OR c) AND NOT d)"},
+		{input: "-(a b)", want: "NOT (a OR b)"},
+		{input: "apple a day -> keeps", want: "((apple OR a OR day OR keeps) AND NOT >)"},
+		{input: `"bobby tables"~3 OR drop`, want: `("bobby tables"~3 OR drop)`},
+		{input: `title:bobby alt:"drop tables" -transcript:(a b)`, want: `((title:bobby OR alt:"drop tables") AND NOT (transcript:a OR transcript:b))`},
+		{input: "http://xkcd.com", want: "http://xkcd.com"},
+		{input: "linux OR NOT cpu", want: "(linux OR NOT cpu)"},
+		{input: "linux OR -cpu video", want: "(linux OR NOT cpu OR video)"},
+		{input: "-cpu OR linux", want: "(NOT cpu OR linux)"},
+		{input: "-cpu linux", want: "(linux AND NOT cpu)"},
+		{input: "-cpu -video", want: "(NOT cpu AND NOT video)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseQuery(tc.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tc.input, err)
+			}
+			fillTerms(q)
+			if got := render(q.Simplify()); got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	cases := []struct {
+		input string
+		pos   int
+	}{
+		{input: "(linux", pos: 1},
+		{input: "linux)", pos: 6},
+		{input: `linux "cpu`, pos: 7},
+		{input: "linux AND", pos: 10},
+		{input: "OR linux", pos: 1},
+		{input: "linux NOT", pos: 10},
+		{input: `"bobby tables"~ drop`, pos: 15},
+		{input: `"bobby tables"~500`, pos: 15},
+		{input: "title: OR cpu", pos: 8},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := ParseQuery(tc.input)
+			if !errors.Is(err, ErrBadArguments) {
+				t.Fatalf("expected ErrBadArguments, got %v", err)
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Pos != tc.pos {
+				t.Fatalf("expected error at position %d, got %v", tc.pos, err)
+			}
+		})
+	}
+}
+
+func TestIndexMatch(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("bobbi", "tabl"),
+		2: tokens("bobbi"),
+		3: tokens("tabl", "drop"),
+	})
+
+	q, err := ParseQuery("bobbi OR tabl -drop")
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	fillTerms(q)
+
+	got := idx.Match(q.Simplify())
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %v", got)
+	}
+	if _, ok := got[3]; ok {
+		t.Fatalf("excluded doc matched: %v", got)
+	}
+}
+
+func TestIndexMatch_Negation(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("bobbi", "tabl"),
+		2: tokens("bobbi"),
+		3: tokens("tabl", "drop"),
+		4: tokens("drop"),
+	})
+
+	cases := []struct {
+		input string
+		want  []int
+	}{
+		{input: "bobbi -drop", want: []int{1, 2}},
+		{input: "bobbi NOT drop", want: []int{1, 2}},
+		{input: "bobbi OR -drop", want: []int{1, 2}},
+		{input: "tabl OR -drop", want: []int{1, 2, 3}},
+		{input: "tabl OR NOT bobbi", want: []int{1, 3, 4}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseQuery(tc.input)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			fillTerms(q)
+
+			got := idx.Match(q.Simplify())
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for _, id := range tc.want {
+				if _, ok := got[id]; !ok {
+					t.Fatalf("expected %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexMatch_Phrase(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: {{Stem: "littl", Pos: 0}, {Stem: "bobbi", Pos: 1}, {Stem: "tabl", Pos: 2}},
+		2: {{Stem: "bobbi", Pos: 0}, {Stem: "drop", Pos: 1}, {Stem: "tabl", Pos: 3}},
+		3: {{Stem: "tabl", Pos: 0}, {Stem: "bobbi", Pos: 9}},
+		4: {{Stem: "tabl", Pos: 4}, {Stem: "bobbi", Pos: 5}},
+	})
+
+	cases := []struct {
+		input string
+		want  []int
+	}{
+		{input: `"bobbi tabl"`, want: []int{1}},
+		{input: `"littl bobbi tabl"`, want: []int{1}},
+		{input: `"bobbi tabl"~2`, want: []int{1, 2, 4}},
+		{input: `"bobbi tabl"~1`, want: []int{1, 4}},
+		{input: `"bobbi tabl"~3 -drop`, want: []int{1, 4}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseQuery(tc.input)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			fillTerms(q)
+
+			got := idx.Match(q.Simplify())
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for _, id := range tc.want {
+				if _, ok := got[id]; !ok {
+					t.Fatalf("expected %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexMatch_Fields(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: {{Stem: "bobbi", Pos: 0, Field: FieldTitle}, {Stem: "tabl", Pos: 1000, Field: FieldAlt}},
+		2: {{Stem: "bobbi", Pos: 0, Field: FieldAlt}, {Stem: "tabl", Pos: 1, Field: FieldAlt}},
+	})
+
+	cases := []struct {
+		input string
+		want  []int
+	}{
+		{input: "title:bobbi", want: []int{1}},
+		{input: "alt:bobbi", want: []int{2}},
+		{input: `alt:"bobbi tabl"`, want: []int{2}},
+		{input: `"bobbi tabl"~5`, want: []int{2}},
+		{input: "transcript:tabl", want: nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseQuery(tc.input)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			fillTerms(q)
+
+			got := idx.Match(q.Simplify())
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for _, id := range tc.want {
+				if _, ok := got[id]; !ok {
+					t.Fatalf("expected %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
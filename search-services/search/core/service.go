@@ -2,141 +2,428 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	defaultLimit = 10
 	maxPhraseLen = 4096
+	// maxOffset bounds how deep pages of results go, whether asked for by
+	// offset or by cursor.
+	maxOffset = 10000
+	// watermarkOverlap is how far back from the watermark a sync looks:
+	// rows are stamped when their transaction starts, so a slow one may
+	// commit rows older than the watermark after it moved.
+	watermarkOverlap = time.Minute
 )
 
 type Service struct {
-	log   *slog.Logger
-	store Storage
-	words Words
-
-	mu    sync.RWMutex
-	index Index
+	log       *slog.Logger
+	store     Storage
+	words     Words
+	snapshots SnapshotStore
+	boosts    FieldBoosts
+
+	// syncMu serializes loading and merging index data, so that older
+	// data never replaces newer.
+	syncMu sync.Mutex
+
+	mu        sync.RWMutex
+	index     *Index
+	vocab     *Vocabulary
+	watermark time.Time
 }
 
-func NewService(log *slog.Logger, store Storage, words Words) (*Service, error) {
-	if log == nil || store == nil || words == nil {
+func NewService(
+	log *slog.Logger, store Storage, words Words, snapshots SnapshotStore, boosts FieldBoosts,
+) (*Service, error) {
+	if log == nil || store == nil || words == nil || snapshots == nil {
 		return nil, ErrNilDependency
 	}
 	return &Service{
-		log:   log,
-		store: store,
-		words: words,
-		index: make(Index),
+		log:       log,
+		store:     store,
+		words:     words,
+		snapshots: snapshots,
+		boosts:    boosts,
+		index:     NewIndex(),
+		vocab:     NewVocabulary(NewIndex(), nil),
 	}, nil
 }
 
 func (s *Service) Search(ctx context.Context, params SearchParams) (SearchResult, error) {
-	limit, err := normalizeLimit(params.Limit)
+	limit, offset, err := pageOf(params)
 	if err != nil {
 		return SearchResult{}, err
 	}
-	phrase, err := sanitizePhrase(params.Phrase)
+	q, err := s.parseQuery(ctx, params.Phrase)
 	if err != nil {
 		return SearchResult{}, err
 	}
+	if q == nil {
+		return SearchResult{}, nil
+	}
+	if q.hasPhrases() {
+		return s.searchPhrases(ctx, params, q, limit, offset)
+	}
 
-	ws, err := s.words.Norm(ctx, phrase)
+	comics, total, err := s.store.SearchComics(ctx, q, limit, offset)
 	if err != nil {
 		return SearchResult{}, err
 	}
-	if len(ws) == 0 {
-		return SearchResult{}, nil
+	if total == 0 {
+		return SearchResult{Suggestions: s.suggestions(strings.TrimSpace(params.Phrase), q)}, nil
 	}
-
-	comics, total, err := s.store.SearchComics(ctx, ws, limit)
-	if err != nil {
+	if err := s.describe(ctx, q, comics); err != nil {
 		return SearchResult{}, err
 	}
-	return SearchResult{Comics: comics, Total: total}, nil
+	return SearchResult{
+		Comics:     comics,
+		Total:      total,
+		NextCursor: nextCursor(params.Phrase, offset, len(comics), total),
+	}, nil
 }
 
-func (s *Service) ISearch(ctx context.Context, params SearchParams) (SearchResult, error) {
-	limit, err := normalizeLimit(params.Limit)
-	if err != nil {
-		return SearchResult{}, err
+// searchPhrases finds the comics of a query with phrases in the index, which
+// keeps the word positions the database does not, and ranks them the way the
+// database does: by the number of query terms they have, then by ID.
+func (s *Service) searchPhrases(ctx context.Context, params SearchParams, q *Query, limit, offset int) (SearchResult, error) {
+	terms := q.PositiveTerms()
+	s.mu.RLock()
+	matched := s.index.Match(q)
+	ranked := make([]scoredID, 0, len(matched))
+	for id := range matched {
+		n := 0
+		for _, term := range terms {
+			if s.index.posting(term, id).ID == id {
+				n++
+			}
+		}
+		ranked = append(ranked, scoredID{id: id, score: float64(n)})
 	}
-	phrase, err := sanitizePhrase(params.Phrase)
+	s.mu.RUnlock()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score == ranked[j].score {
+			return ranked[i].id < ranked[j].id
+		}
+		return ranked[i].score > ranked[j].score
+	})
+	return s.page(ctx, params, q, ranked, limit, offset)
+}
+
+func (s *Service) ISearch(ctx context.Context, params SearchParams) (SearchResult, error) {
+	limit, offset, err := pageOf(params)
 	if err != nil {
 		return SearchResult{}, err
 	}
-
-	ws, err := s.words.Norm(ctx, phrase)
+	q, err := s.parseQuery(ctx, params.Phrase)
 	if err != nil {
 		return SearchResult{}, err
 	}
-	ws = deduplicateWords(ws)
-	if len(ws) == 0 {
+	if q == nil {
 		return SearchResult{}, nil
 	}
+	if params.Fuzzy {
+		s.expandFuzzy(q)
+	}
+
+	return s.page(ctx, params, q, s.rankIDs(q), limit, offset)
+}
 
-	ranked := s.rankIDs(ws)
+// page describes the comics of the ranked ones the page asks for.
+func (s *Service) page(
+	ctx context.Context, params SearchParams, q *Query, ranked []scoredID, limit, offset int,
+) (SearchResult, error) {
 	total := len(ranked)
 	if total == 0 {
-		return SearchResult{}, nil
+		return SearchResult{Suggestions: s.suggestions(strings.TrimSpace(params.Phrase), q)}, nil
 	}
-
-	if len(ranked) > limit {
-		ranked = ranked[:limit]
+	if offset >= total {
+		return SearchResult{Total: total}, nil
 	}
+	ranked = ranked[offset:min(total, offset+limit)]
 
-	comics, err := s.store.GetComicsByIDs(ctx, ranked)
+	ids := make([]int, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.id
+	}
+	comics, err := s.store.GetComicsByIDs(ctx, ids)
 	if err != nil {
 		return SearchResult{}, err
 	}
 	ordered := orderComics(comics, ranked)
+	if err := s.describe(ctx, q, ordered); err != nil {
+		return SearchResult{}, err
+	}
 
 	return SearchResult{
-		Comics: ordered,
-		Total:  total,
+		Comics:     ordered,
+		Total:      total,
+		NextCursor: nextCursor(params.Phrase, offset, len(ranked), total),
 	}, nil
 }
 
-func (s *Service) RebuildIndex(ctx context.Context) error {
-	data, err := s.store.LoadIndexData(ctx)
+func (s *Service) parseQuery(ctx context.Context, phrase string) (*Query, error) {
+	phrase, err := sanitizePhrase(phrase)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	q, err := ParseQuery(phrase)
+	if err != nil {
+		return nil, err
 	}
+	if err := s.normalizeQuery(ctx, q); err != nil {
+		return nil, err
+	}
+	q = q.Simplify()
+	s.expandSynonyms(ctx, phrase, q)
+	return q, nil
+}
 
-	newIndex := make(Index, len(data))
-	for id, words := range data {
-		if len(words) == 0 {
-			continue
-		}
-		seen := make(map[string]struct{}, len(words))
-		for _, word := range words {
-			if word == "" {
-				continue
-			}
-			if _, ok := seen[word]; ok {
-				continue
+// normalizeQuery stems the texts of all the leaves of q in one call to the
+// words service.
+func (s *Service) normalizeQuery(ctx context.Context, q *Query) error {
+	var leaves []*Query
+	var texts []string
+	var walk func(n *Query)
+	walk = func(n *Query) {
+		switch {
+		case n.Op != QueryTerm && n.Op != QueryPhrase:
+			for _, c := range n.Children {
+				walk(c)
 			}
-			seen[word] = struct{}{}
-			newIndex[word] = append(newIndex[word], id)
+		case strings.TrimSpace(n.Text) != "":
+			leaves = append(leaves, n)
+			texts = append(texts, n.Text)
 		}
 	}
+	walk(q)
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	tokens, err := s.words.NormBatch(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(tokens) != len(leaves) {
+		return fmt.Errorf("got words of %d query words out of %d", len(tokens), len(leaves))
+	}
+	for i, leaf := range leaves {
+		leaf.setTokens(tokens[i])
+	}
+	return nil
+}
+
+// setTokens sets the stems of a leaf: in order and with their offsets for a
+// phrase, once each for a term.
+func (q *Query) setTokens(tokens []Token) {
+	if q.Op == QueryPhrase {
+		q.Terms = make([]string, 0, len(tokens))
+		q.Offsets = make([]int, 0, len(tokens))
+		for _, t := range tokens {
+			q.Terms = append(q.Terms, t.Stem)
+			q.Offsets = append(q.Offsets, t.Pos)
+		}
+		return
+	}
+	ws := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		ws = append(ws, t.Stem)
+	}
+	q.Terms = deduplicateWords(ws)
+}
+
+func (s *Service) GetComic(ctx context.Context, id int) (ComicInfo, error) {
+	if id <= 0 {
+		return ComicInfo{}, ErrBadArguments
+	}
+	return s.store.GetComic(ctx, id)
+}
 
-	for word, ids := range newIndex {
-		sort.Ints(ids)
-		newIndex[word] = ids
+// Suggest completes prefix with the indexed words. Index terms are stems,
+// so they are completed by the words they stand for in the comics.
+func (s *Service) Suggest(_ context.Context, prefix string, limit int) ([]Completion, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || limit < 0 {
+		return nil, ErrBadArguments
+	}
+	if len(prefix) > maxPhraseLen {
+		return nil, ErrRequestTooLarge
 	}
+	if limit == 0 {
+		limit = defaultCompletions
+	}
+	limit = min(limit, maxCompletions)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stems := append(s.vocab.Complete(prefix, maxCompletions), s.vocab.stemsOf(prefix)...)
+	sort.SliceStable(stems, func(i, j int) bool { return stems[i].Count > stems[j].Count })
+	return s.vocab.completions(prefix, stems, limit), nil
+}
+
+func (s *Service) RebuildIndex(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	data, watermark, err := s.store.LoadIndexData(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	newIndex := BuildIndex(data)
+	vocab := NewVocabulary(newIndex, s.surfacesOf(ctx, slices.Collect(maps.Keys(data))))
 
 	s.mu.Lock()
 	s.index = newIndex
+	s.vocab = vocab
+	s.watermark = watermark
 	s.mu.Unlock()
 
-	s.log.Info("index rebuilt", "entries", len(newIndex))
+	s.log.Info("index rebuilt", "entries", len(newIndex.Postings), "docs", newIndex.Docs())
+	return nil
+}
+
+// UpdateIndex merges the comics changed since the watermark, which the
+// given ones normally are, and drops the given ones gone from the database.
+func (s *Service) UpdateIndex(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	data, watermark, err := s.store.LoadIndexData(ctx, s.syncSince())
+	if err != nil {
+		return err
+	}
+	var rest []int
+	for _, id := range ids {
+		if _, ok := data[id]; !ok {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) > 0 {
+		found, err := s.store.LoadIndexDataByIDs(ctx, rest)
+		if err != nil {
+			return err
+		}
+		for _, id := range rest {
+			data[id] = found[id]
+		}
+	}
+
+	docs := s.merge(ctx, data, nil, watermark)
+	s.log.Info("index updated", "comics", len(ids), "docs", docs)
+	return nil
+}
+
+func (s *Service) SyncIndex(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	data, watermark, err := s.store.LoadIndexData(ctx, s.syncSince())
+	if err != nil {
+		return err
+	}
+	ids, err := s.store.IDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	present := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		present[id] = struct{}{}
+	}
+	var stale []int
+	s.mu.RLock()
+	for id := range s.index.DocLens {
+		if _, ok := present[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.RUnlock()
+
+	s.merge(ctx, data, stale, watermark)
+	s.log.Debug("index synced", "merged", len(data), "removed", len(stale))
 	return nil
 }
 
+// syncSince is the time rows are loaded from, the watermark less the overlap.
+func (s *Service) syncSince() time.Time {
+	if s.watermark.IsZero() {
+		return time.Time{}
+	}
+	return s.watermark.Add(-watermarkOverlap)
+}
+
+// merge replaces the documents of data, removes the stale ones and moves the
+// watermark forward. It returns the number of documents indexed.
+func (s *Service) merge(ctx context.Context, data map[int][]Token, stale []int, watermark time.Time) int {
+	var ids []int
+	for id, tokens := range data {
+		if len(tokens) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	surfaces := s.surfacesOf(ctx, ids)
+	for id, tokens := range data {
+		if len(tokens) == 0 {
+			surfaces[id] = nil
+		}
+	}
+	for _, id := range stale {
+		surfaces[id] = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.index.Remove(stale)
+	for word := range s.index.Merge(data) {
+		changed[word] = struct{}{}
+	}
+	s.vocab.Update(s.index, changed, surfaces)
+	if watermark.After(s.watermark) {
+		s.watermark = watermark
+	}
+	return s.index.Docs()
+}
+
+func (s *Service) RestoreIndex(ctx context.Context) error {
+	snapshot, err := s.snapshots.Load()
+	if err != nil {
+		return err
+	}
+
+	s.syncMu.Lock()
+	vocab := NewVocabulary(snapshot.Index, s.surfacesOf(ctx, slices.Collect(maps.Keys(snapshot.Index.DocLens))))
+	s.mu.Lock()
+	s.index = snapshot.Index
+	s.vocab = vocab
+	s.watermark = snapshot.Watermark
+	s.mu.Unlock()
+	s.syncMu.Unlock()
+
+	s.log.Info("index restored from snapshot",
+		"docs", snapshot.Index.Docs(), "watermark", snapshot.Watermark)
+	return s.SyncIndex(ctx)
+}
+
+func (s *Service) SaveIndex(_ context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshots.Save(Snapshot{Index: s.index, Watermark: s.watermark})
+}
+
 func normalizeLimit(limit int) (int, error) {
 	if limit < 0 {
 		return 0, ErrBadArguments
@@ -147,6 +434,33 @@ func normalizeLimit(limit int) (int, error) {
 	return limit, nil
 }
 
+func pageOf(params SearchParams) (int, int, error) {
+	limit, err := normalizeLimit(params.Limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset := params.Offset
+	if params.Cursor != "" {
+		if offset, err = decodeCursor(strings.TrimSpace(params.Phrase), params.Cursor); err != nil {
+			return 0, 0, err
+		}
+	}
+	if offset < 0 {
+		return 0, 0, ErrBadArguments
+	}
+	if offset > maxOffset {
+		return 0, 0, fmt.Errorf("%w: offset above %d", ErrBadArguments, maxOffset)
+	}
+	return limit, offset, nil
+}
+
+func nextCursor(phrase string, offset, count, total int) string {
+	if count == 0 || offset+count >= total || offset+count > maxOffset {
+		return ""
+	}
+	return encodeCursor(strings.TrimSpace(phrase), offset+count)
+}
+
 func sanitizePhrase(phrase string) (string, error) {
 	p := strings.TrimSpace(phrase)
 	if p == "" {
@@ -177,52 +491,50 @@ func deduplicateWords(words []string) []string {
 	return result
 }
 
-func (s *Service) rankIDs(words []string) []int {
+// expandFuzzy attaches index terms similar to the stems of every positive
+// leaf; negated leaves keep excluding exact stems only.
+func (s *Service) expandFuzzy(q *Query) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-
-	if len(s.index) == 0 || len(words) == 0 {
-		return nil
-	}
-
-	counts := make(map[int]int)
-	for _, word := range words {
-		ids, ok := s.index[word]
-		if !ok {
-			continue
-		}
-		for _, id := range ids {
-			counts[id]++
+	for _, leaf := range q.PositiveLeaves() {
+		for i, term := range leaf.Terms {
+			for _, v := range s.index.Similar(term) {
+				leaf.addVariant(i, v)
+			}
 		}
 	}
+}
 
-	ranked := make([]struct {
-		id      int
-		matches int
-	}, 0, len(counts))
+type scoredID struct {
+	id    int
+	score float64
+}
 
-	for id, matches := range counts {
-		ranked = append(ranked, struct {
-			id      int
-			matches int
-		}{id: id, matches: matches})
+func (s *Service) rankIDs(q *Query) []scoredID {
+	s.mu.RLock()
+	matched := s.index.Match(q)
+	scores := s.index.ScoreWeighted(q.WeightedTerms(), s.boosts)
+	s.mu.RUnlock()
+
+	if len(matched) == 0 {
+		return nil
+	}
+
+	ranked := make([]scoredID, 0, len(matched))
+	for id := range matched {
+		ranked = append(ranked, scoredID{id: id, score: scores[id]})
 	}
 
 	sort.Slice(ranked, func(i, j int) bool {
-		if ranked[i].matches == ranked[j].matches {
+		if ranked[i].score == ranked[j].score {
 			return ranked[i].id < ranked[j].id
 		}
-		return ranked[i].matches > ranked[j].matches
+		return ranked[i].score > ranked[j].score
 	})
-
-	ids := make([]int, len(ranked))
-	for i, r := range ranked {
-		ids[i] = r.id
-	}
-	return ids
+	return ranked
 }
 
-func orderComics(source []Comic, order []int) []Comic {
+func orderComics(source []Comic, order []scoredID) []Comic {
 	if len(source) == 0 || len(order) == 0 {
 		return nil
 	}
@@ -233,8 +545,9 @@ func orderComics(source []Comic, order []int) []Comic {
 	}
 
 	result := make([]Comic, 0, len(order))
-	for _, id := range order {
-		if c, ok := m[id]; ok {
+	for _, r := range order {
+		if c, ok := m[r.id]; ok {
+			c.Score = r.score
 			result = append(result, c)
 		}
 	}
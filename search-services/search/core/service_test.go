@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// normWords stems the words of phrases by lowercasing them and counts its
+// calls.
+type normWords struct {
+	expandingWords
+	calls *int
+}
+
+func (w normWords) NormBatch(_ context.Context, phrases []string) ([][]Token, error) {
+	*w.calls++
+	out := make([][]Token, len(phrases))
+	for i, phrase := range phrases {
+		for pos, word := range testWordRe.FindAllString(phrase, -1) {
+			out[i] = append(out[i], Token{Stem: strings.ToLower(word), Pos: pos})
+		}
+	}
+	return out, nil
+}
+
+// comicStore serves comics by their IDs.
+type comicStore struct {
+	Storage
+}
+
+func (comicStore) GetComicsByIDs(_ context.Context, ids []int) ([]Comic, error) {
+	comics := make([]Comic, len(ids))
+	for i, id := range ids {
+		comics[i] = Comic{ID: id}
+	}
+	return comics, nil
+}
+
+func (comicStore) GetComicTexts(context.Context, []int) (map[int]ComicText, error) {
+	return nil, nil
+}
+
+func TestService_SearchPhrases(t *testing.T) {
+	calls := 0
+	s := &Service{
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		words: normWords{calls: &calls},
+		store: comicStore{},
+		index: BuildIndex(map[int][]Token{
+			1: tokens("bobby", "tables"),
+			2: tokens("tables", "of", "little", "bobby"),
+			3: tokens("little", "bobby", "tables"),
+			4: tokens("bobby", "drop", "tables"),
+		}),
+	}
+
+	cases := []struct {
+		phrase string
+		limit  int
+		want   []int
+		total  int
+	}{
+		{`"bobby tables"`, 10, []int{1, 3}, 2},
+		{`"bobby tables"~1`, 10, []int{1, 3, 4}, 3},
+		{`bobby -"bobby tables"`, 10, []int{2, 4}, 2},
+		{`"bobby tables"~1 | drop`, 2, []int{4, 1}, 3},
+	}
+	for _, c := range cases {
+		calls = 0
+		res, err := s.Search(context.Background(), SearchParams{Phrase: c.phrase, Limit: c.limit})
+		if err != nil {
+			t.Fatalf("%s: %v", c.phrase, err)
+		}
+		if calls != 1 {
+			t.Fatalf("%s: expected the query normalized in one call, got %d", c.phrase, calls)
+		}
+		got := make([]int, len(res.Comics))
+		for i, comic := range res.Comics {
+			got[i] = comic.ID
+		}
+		if !slices.Equal(got, c.want) || res.Total != c.total {
+			t.Fatalf("%s: expected %v of %d, got %v of %d", c.phrase, c.want, c.total, got, res.Total)
+		}
+	}
+}
+
+// stampedStore keeps index data with the time each comic was written.
+type stampedStore struct {
+	Storage
+	data    map[int][]Token
+	stamped map[int]time.Time
+}
+
+func (s *stampedStore) put(id int, at time.Time, words ...string) {
+	s.data[id] = tokens(words...)
+	s.stamped[id] = at
+}
+
+func (s *stampedStore) LoadIndexData(_ context.Context, since time.Time) (map[int][]Token, time.Time, error) {
+	out := make(map[int][]Token)
+	watermark := since
+	for id, at := range s.stamped {
+		if !at.Before(since) {
+			out[id] = s.data[id]
+			if at.After(watermark) {
+				watermark = at
+			}
+		}
+	}
+	return out, watermark, nil
+}
+
+func (s *stampedStore) LoadIndexDataByIDs(_ context.Context, ids []int) (map[int][]Token, error) {
+	out := make(map[int][]Token)
+	for _, id := range ids {
+		if tokens, ok := s.data[id]; ok {
+			out[id] = tokens
+		}
+	}
+	return out, nil
+}
+
+func (*stampedStore) GetComicTexts(context.Context, []int) (map[int]ComicText, error) {
+	return nil, nil
+}
+
+func TestService_UpdateIndex(t *testing.T) {
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	store := &stampedStore{data: map[int][]Token{}, stamped: map[int]time.Time{}}
+	store.put(1, start, "bobby")
+	s := &Service{
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		store: store,
+		index: NewIndex(),
+	}
+	ctx := context.Background()
+	if err := s.RebuildIndex(ctx); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	// 2 is committed late by a transaction started before the watermark.
+	store.put(2, start.Add(-10*time.Second), "tables")
+	store.put(3, start.Add(time.Second), "drop")
+	if err := s.UpdateIndex(ctx, []int{3}); err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	for _, id := range []int{1, 2, 3} {
+		if !s.index.Has(id) {
+			t.Fatalf("expected comic %d indexed", id)
+		}
+	}
+	if !s.watermark.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the watermark moved to the newest comic, got %v", s.watermark)
+	}
+
+	delete(store.data, 1)
+	delete(store.stamped, 1)
+	if err := s.UpdateIndex(ctx, []int{1}); err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	if s.index.Has(1) {
+		t.Fatalf("expected the deleted comic dropped")
+	}
+}
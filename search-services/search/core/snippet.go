@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	snippetWords   = 30
+	snippetContext = 8
+
+	// maxDocumentLen mirrors the limit of the words service on a phrase of
+	// a batch, far above any xkcd transcript.
+	maxDocumentLen = 64 << 10
+)
+
+// describe fills in the highlighted snippet and the matched query terms of
+// the found comics.
+func (s *Service) describe(ctx context.Context, q *Query, comics []Comic) error {
+	if len(comics) == 0 {
+		return nil
+	}
+	ids := make([]int, len(comics))
+	for i, c := range comics {
+		ids[i] = c.ID
+	}
+	texts, err := s.store.GetComicTexts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	leaves := q.PositiveLeaves()
+	stems := make(map[string]struct{})
+	for _, term := range q.WeightedTerms() {
+		stems[term.Term] = struct{}{}
+	}
+	for i := range comics {
+		comics[i].MatchedTerms = matchedTerms(leaves, texts[comics[i].ID].Words)
+	}
+	s.snippets(ctx, comics, texts, stems)
+	return nil
+}
+
+// snippets cuts the snippet of every comic out of its alt text or its
+// transcript, whichever has more hits; the alt text wins ties. The texts of
+// the whole page are analyzed in one call to the words service.
+func (s *Service) snippets(ctx context.Context, comics []Comic, texts map[int]ComicText, stems map[string]struct{}) {
+	type source struct {
+		comic int
+		text  string
+	}
+	var sources []source
+	for i, c := range comics {
+		text := texts[c.ID]
+		for _, t := range []string{text.Alt, text.Transcript} {
+			if strings.TrimSpace(t) == "" {
+				continue
+			}
+			sources = append(sources, source{comic: i, text: truncateUTF8(t, maxDocumentLen)})
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	phrases := make([]string, len(sources))
+	for i, src := range sources {
+		phrases[i] = src.text
+	}
+	analyzed, err := s.words.AnalyzeBatch(ctx, phrases)
+	if err == nil && len(analyzed) != len(phrases) {
+		err = fmt.Errorf("words service analyzed %d of %d texts", len(analyzed), len(phrases))
+	}
+	if err != nil {
+		s.log.Warn("failed to analyze snippet sources", "comics", len(comics), "error", err)
+		return
+	}
+
+	best := make([]int, len(comics))
+	for i := range best {
+		best[i] = -1
+	}
+	for i, src := range sources {
+		snippet, hits := buildSnippet(src.text, analyzed[i], stems)
+		if hits > best[src.comic] {
+			comics[src.comic].Snippet, best[src.comic] = snippet, hits
+		}
+	}
+}
+
+// buildSnippet returns an HTML-escaped window of text around the first hit
+// with every word whose stem is among stems wrapped in <b>, and the number of
+// hits inside the window. Words come from the words service in text order
+// with their byte offsets.
+func buildSnippet(text string, words []Word, stems map[string]struct{}) (string, int) {
+	valid := make([]Word, 0, len(words))
+	prev := 0
+	for _, w := range words {
+		if w.Start < prev || w.End < w.Start || w.End > len(text) {
+			continue
+		}
+		valid = append(valid, w)
+		prev = w.End
+	}
+	words = valid
+	if len(words) == 0 {
+		return "", 0
+	}
+
+	first := -1
+	for i, w := range words {
+		if _, ok := stems[w.Stem]; ok && w.Stem != "" {
+			first = i
+			break
+		}
+	}
+
+	start := max(0, first-snippetContext)
+	end := min(len(words), start+snippetWords)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	hits := 0
+	prev = words[start].Start
+	for i := start; i < end; i++ {
+		w := words[i]
+		b.WriteString(html.EscapeString(text[prev:w.Start]))
+		word := html.EscapeString(text[w.Start:w.End])
+		if _, ok := stems[w.Stem]; ok && w.Stem != "" {
+			b.WriteString("<b>" + word + "</b>")
+			hits++
+		} else {
+			b.WriteString(word)
+		}
+		prev = w.End
+	}
+	if end < len(words) {
+		b.WriteString(" …")
+	}
+	return strings.Join(strings.Fields(b.String()), " "), hits
+}
+
+// matchedTerms returns the text of every positive query leaf found among the
+// comic stems: any stem for a term, all of them for a phrase, a variant
+// standing for its stem.
+func matchedTerms(leaves []*Query, words []string) []string {
+	have := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		have[w] = struct{}{}
+	}
+	var out []string
+	seen := make(map[string]struct{})
+	for _, leaf := range leaves {
+		found := 0
+		for i := range leaf.Terms {
+			for _, term := range leaf.alternatives(i) {
+				if _, ok := have[term]; ok {
+					found++
+					break
+				}
+			}
+		}
+		matched := found > 0
+		if leaf.Op == QueryPhrase {
+			matched = found == len(leaf.Terms)
+		}
+		if _, ok := seen[leaf.Text]; ok || !matched {
+			continue
+		}
+		seen[leaf.Text] = struct{}{}
+		out = append(out, leaf.Text)
+	}
+	return out
+}
+
+func truncateUTF8(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var testWordRe = regexp.MustCompile(`[\p{L}\p{M}\p{N}]+`)
+
+// analyze splits text into words like the words service and gives them the
+// stems of tokens.
+func analyze(text string, tokens []Token) []Word {
+	spans := testWordRe.FindAllStringIndex(text, -1)
+	words := make([]Word, len(spans))
+	for i, span := range spans {
+		words[i] = Word{Start: span[0], End: span[1]}
+	}
+	for _, t := range tokens {
+		words[t.Pos].Stem = t.Stem
+	}
+	return words
+}
+
+// analyzingWords stems words by lowercasing them and counts its calls.
+type analyzingWords struct {
+	expandingWords
+	calls *int
+}
+
+func (w analyzingWords) AnalyzeBatch(_ context.Context, texts []string) ([][]Word, error) {
+	*w.calls++
+	out := make([][]Word, len(texts))
+	for i, text := range texts {
+		for _, span := range testWordRe.FindAllStringIndex(text, -1) {
+			stem := strings.ToLower(text[span[0]:span[1]])
+			out[i] = append(out[i], Word{Stem: stem, Start: span[0], End: span[1]})
+		}
+	}
+	return out, nil
+}
+
+func TestService_Snippets(t *testing.T) {
+	calls := 0
+	s := &Service{
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		words: analyzingWords{calls: &calls},
+	}
+	long := strings.Repeat("filler ", 2000) + "the Bobby hit"
+	comics := []Comic{{ID: 1}, {ID: 2}, {ID: 3}}
+	texts := map[int]ComicText{
+		1: {Alt: "bobby here", Transcript: "bobby and bobby there"},
+		2: {Alt: "nothing", Transcript: long},
+		3: {},
+	}
+
+	s.snippets(context.Background(), comics, texts, map[string]struct{}{"bobby": {}})
+	if calls != 1 {
+		t.Fatalf("expected one words call per page, got %d", calls)
+	}
+	if got := comics[0].Snippet; got != "<b>bobby</b> and <b>bobby</b> there" {
+		t.Fatalf("expected the transcript with more hits, got %q", got)
+	}
+	if got := comics[1].Snippet; !strings.Contains(got, "<b>Bobby</b> hit") {
+		t.Fatalf("expected a hit past 4 KiB of transcript, got %q", got)
+	}
+	if got := comics[2].Snippet; got != "" {
+		t.Fatalf("expected no snippet without texts, got %q", got)
+	}
+}
+
+func TestBuildSnippet_HighlightsAndEscapes(t *testing.T) {
+	text := "Did you really name your son Robert'); DROP TABLE Students;-- ?\n<Oh>, yes. Little Bobby Tables, we call him."
+	tokens := []Token{
+		{Stem: "name", Pos: 3}, {Stem: "son", Pos: 5}, {Stem: "robert", Pos: 6},
+		{Stem: "drop", Pos: 7}, {Stem: "tabl", Pos: 8}, {Stem: "student", Pos: 9},
+		{Stem: "oh", Pos: 10}, {Stem: "littl", Pos: 12}, {Stem: "bobbi", Pos: 13},
+		{Stem: "tabl", Pos: 14}, {Stem: "call", Pos: 16},
+	}
+
+	got, hits := buildSnippet(text, analyze(text, tokens), map[string]struct{}{"tabl": {}})
+	if hits != 2 {
+		t.Fatalf("expected 2 hits, got %d in %q", hits, got)
+	}
+	want := "Did you really name your son Robert&#39;); DROP <b>TABLE</b> Students;-- ? &lt;Oh&gt;, yes. Little Bobby <b>Tables</b>, we call him"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestBuildSnippet_Window(t *testing.T) {
+	words := make([]string, 100)
+	tokens := make([]Token, 0, len(words))
+	for i := range words {
+		words[i] = "w"
+		tokens = append(tokens, Token{Stem: "w", Pos: i})
+	}
+	words[50] = "hit"
+	tokens[50].Stem = "hit"
+
+	text := strings.Join(words, " ")
+	got, hits := buildSnippet(text, analyze(text, tokens), map[string]struct{}{"hit": {}})
+	if hits != 1 || !strings.HasPrefix(got, "… ") || !strings.HasSuffix(got, " …") {
+		t.Fatalf("unexpected snippet %q with %d hits", got, hits)
+	}
+	if strings.Count(got, "w") != snippetWords-1 {
+		t.Fatalf("unexpected window %q", got)
+	}
+}
+
+func TestBuildSnippet_Cyrillic(t *testing.T) {
+	tokens := []Token{{Stem: "кошк", Pos: 0}, {Stem: "собак", Pos: 2}, {Stem: "linux", Pos: 3}}
+	text := "Кошки и собаки, Linux!"
+	got, hits := buildSnippet(text, analyze(text, tokens), map[string]struct{}{"собак": {}})
+	if want := "Кошки и <b>собаки</b>, Linux"; got != want || hits != 1 {
+		t.Fatalf("expected %q, got %q with %d hits", want, got, hits)
+	}
+}
+
+func TestBuildSnippet_BadOffsets(t *testing.T) {
+	words := []Word{{Stem: "bobbi", Start: 0, End: 5}, {Stem: "tabl", Start: 3, End: 9}, {Stem: "x", Start: 6, End: 99}}
+	got, hits := buildSnippet("Bobby Tables", words, map[string]struct{}{"bobbi": {}})
+	if got != "<b>Bobby</b>" || hits != 1 {
+		t.Fatalf("unexpected snippet %q with %d hits", got, hits)
+	}
+}
+
+func TestMatchedTerms(t *testing.T) {
+	q, err := ParseQuery(`"bobby tables" OR linux -drop`)
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	fillTerms(q)
+
+	got := matchedTerms(q.Simplify().PositiveLeaves(), []string{"bobby", "tables", "drop"})
+	if len(got) != 1 || got[0] != "bobby tables" {
+		t.Fatalf("unexpected matched terms: %v", got)
+	}
+}
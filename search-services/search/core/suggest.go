@@ -0,0 +1,89 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+const maxSuggestions = 3
+
+type correction struct {
+	leaf       *Query
+	candidates []string
+}
+
+// suggestions returns corrected versions of a phrase that found nothing.
+// Every query word whose stem is missing from the index is replaced by a
+// similar index term; candidates are ranked by document frequency scaled
+// down with every edit.
+func (s *Service) suggestions(phrase string, q *Query) []string {
+	if q == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	var corrections []correction
+	for _, leaf := range q.PositiveLeaves() {
+		if leaf.Op != QueryTerm || len(leaf.Terms) != 1 {
+			continue
+		}
+		term := leaf.Terms[0]
+		if len(s.index.Postings[term]) > 0 {
+			continue
+		}
+		variants := s.index.Similar(term)
+		if len(variants) == 0 {
+			continue
+		}
+		weights := make(map[string]float64, len(variants))
+		for _, v := range variants {
+			weights[v.Term] = float64(len(s.index.Postings[v.Term])) * v.Weight
+		}
+		sort.SliceStable(variants, func(i, j int) bool {
+			return weights[variants[i].Term] > weights[variants[j].Term]
+		})
+		c := correction{leaf: leaf}
+		for _, v := range variants {
+			c.candidates = append(c.candidates, surfaceForm(leaf.Text, term, v.Term))
+		}
+		corrections = append(corrections, c)
+	}
+	s.mu.RUnlock()
+
+	if len(corrections) == 0 {
+		return nil
+	}
+	sort.Slice(corrections, func(i, j int) bool { return corrections[i].leaf.Pos > corrections[j].leaf.Pos })
+
+	var out []string
+	seen := make(map[string]struct{})
+	for k := 0; k < maxSuggestions; k++ {
+		runes := []rune(phrase)
+		for _, c := range corrections {
+			candidate := c.candidates[min(k, len(c.candidates)-1)]
+			start := c.leaf.Pos - 1
+			end := start + len([]rune(c.leaf.Text))
+			if start < 0 || end > len(runes) {
+				continue
+			}
+			runes = append(runes[:start], append([]rune(candidate), runes[end:]...)...)
+		}
+		suggestion := string(runes)
+		if _, ok := seen[suggestion]; ok {
+			continue
+		}
+		seen[suggestion] = struct{}{}
+		out = append(out, suggestion)
+	}
+	return out
+}
+
+// surfaceForm turns a corrected stem back into a word by keeping the ending
+// the user typed after the misspelled stem.
+func surfaceForm(word, stem, corrected string) string {
+	lower := strings.ToLower(word)
+	if strings.HasPrefix(lower, stem) {
+		return corrected + lower[len(stem):]
+	}
+	return corrected
+}
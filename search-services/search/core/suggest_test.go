@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+func TestSuggestions(t *testing.T) {
+	s := &Service{index: BuildIndex(map[int][]Token{
+		1: tokens("python", "linux"),
+		2: tokens("python", "linux"),
+		3: tokens("pythan"),
+		4: tokens("cpu"),
+	})}
+
+	phrase := "Pythns -cpu linux"
+	q, err := ParseQuery(phrase)
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	// (pythns OR linux) AND NOT cpu
+	q.Children[0].Children[0].Terms = []string{"pythn"}
+	q.Children[0].Children[1].Terms = []string{"linux"}
+	q.Children[1].Children[0].Terms = []string{"cpu"}
+
+	got := s.suggestions(phrase, q)
+	if len(got) != 2 || got[0] != "pythons -cpu linux" || got[1] != "pythans -cpu linux" {
+		t.Fatalf("unexpected suggestions: %q", got)
+	}
+}
+
+func TestSuggestions_KnownTerms(t *testing.T) {
+	s := &Service{index: BuildIndex(map[int][]Token{1: tokens("python")})}
+	q := &Query{Op: QueryTerm, Text: "python", Terms: []string{"python"}, Pos: 1}
+	if got := s.suggestions("python", q); got != nil {
+		t.Fatalf("expected no suggestions, got %q", got)
+	}
+}
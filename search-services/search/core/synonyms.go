@@ -0,0 +1,55 @@
+package core
+
+import "context"
+
+const synonymWeight = 0.5
+
+// expandSynonyms widens the positive term leaves with the expansions of their
+// stems from the words service: one-word synonyms become variants, longer
+// forms phrases ORed with the leaf. Both count for less than the query words.
+// The query is left as is when the words service cannot expand it.
+func (s *Service) expandSynonyms(ctx context.Context, phrase string, q *Query) {
+	if q == nil {
+		return
+	}
+	expansions, err := s.words.Expand(ctx, phrase)
+	if err != nil {
+		s.log.Warn("failed to expand query", "error", err)
+		return
+	}
+	byStem := make(map[string][]Expansion, len(expansions))
+	for _, e := range expansions {
+		byStem[e.Stem] = append(byStem[e.Stem], e)
+	}
+	if len(byStem) == 0 {
+		return
+	}
+
+	for _, leaf := range q.PositiveLeaves() {
+		if leaf.Op != QueryTerm {
+			continue
+		}
+		var phrases []*Query
+		for i, term := range leaf.Terms {
+			for _, e := range byStem[term] {
+				if len(e.Terms) == 1 {
+					leaf.addVariant(i, Variant{Term: e.Terms[0], Weight: synonymWeight})
+					continue
+				}
+				phrases = append(phrases, &Query{
+					Op:      QueryPhrase,
+					Text:    leaf.Text,
+					Terms:   e.Terms,
+					Offsets: e.Positions,
+					Fields:  leaf.Fields,
+					Pos:     leaf.Pos,
+					Weight:  synonymWeight,
+				})
+			}
+		}
+		if len(phrases) > 0 {
+			orig := *leaf
+			*leaf = Query{Op: QueryOr, Children: append([]*Query{&orig}, phrases...), Pos: orig.Pos}
+		}
+	}
+}
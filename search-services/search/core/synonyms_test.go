@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+type expandingWords struct {
+	expansions []Expansion
+}
+
+func (expandingWords) NormBatch(_ context.Context, phrases []string) ([][]Token, error) {
+	return make([][]Token, len(phrases)), nil
+}
+
+func (expandingWords) AnalyzeBatch(context.Context, []string) ([][]Word, error) {
+	return nil, nil
+}
+
+func (w expandingWords) Expand(context.Context, string) ([]Expansion, error) {
+	return w.expansions, nil
+}
+
+func TestExpandSynonyms(t *testing.T) {
+	s := &Service{
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		words: expandingWords{expansions: []Expansion{
+			{Stem: "car", Terms: []string{"automobil"}, Positions: []int{0}},
+			{Stem: "ai", Terms: []string{"artifici", "intellig"}, Positions: []int{0, 1}},
+		}},
+	}
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("car"),
+		2: tokens("automobil"),
+		3: tokens("artifici", "intellig"),
+		4: tokens("intellig", "artifici"),
+		5: tokens("ai", "car"),
+	})
+
+	q := &Query{Op: QueryOr, Children: []*Query{
+		{Op: QueryTerm, Text: "cars", Terms: []string{"car"}, Pos: 1},
+		{Op: QueryTerm, Text: "AI", Terms: []string{"ai"}, Pos: 6},
+	}}
+	s.expandSynonyms(context.Background(), "cars AI", q)
+
+	got := idx.Match(q)
+	for _, id := range []int{1, 2, 3, 5} {
+		if _, ok := got[id]; !ok {
+			t.Fatalf("expected comic %d to match, got %v", id, got)
+		}
+	}
+	if _, ok := got[4]; ok {
+		t.Fatalf("expansion phrase matched out of order: %v", got)
+	}
+
+	weights := make(map[string]float64)
+	for _, term := range q.WeightedTerms() {
+		weights[term.Term] = term.Weight
+	}
+	if weights["car"] != 1 || weights["ai"] != 1 {
+		t.Fatalf("query words must keep full weight: %v", weights)
+	}
+	for _, term := range []string{"automobil", "artifici", "intellig"} {
+		if weights[term] != synonymWeight {
+			t.Fatalf("expected %q weighted %v, got %v", term, synonymWeight, weights[term])
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultCompletions = 10
+	maxCompletions     = 50
+
+	// maxSurfaceComics bounds the comics whose texts are loaded at once.
+	maxSurfaceComics = 500
+)
+
+type Completion struct {
+	Term  string
+	Count int
+}
+
+// Surfaces maps the stems of a comic to the words they stand for in its
+// texts.
+type Surfaces map[string][]string
+
+// Vocabulary is the sorted list of index terms with their document
+// frequencies; terms sharing a prefix form a contiguous range. As terms are
+// stems, it also keeps the words each term stands for, most frequent first.
+type Vocabulary struct {
+	terms    []string
+	df       map[string]int
+	docs     map[int]Surfaces
+	counts   map[string]map[string]int
+	surfaces map[string][]string
+}
+
+// NewVocabulary makes the vocabulary of idx with the surfaces of its
+// comics; comics without surfaces complete with their stems.
+func NewVocabulary(idx *Index, surfaces map[int]Surfaces) *Vocabulary {
+	terms := make([]string, 0, len(idx.Postings))
+	df := make(map[string]int, len(idx.Postings))
+	for term, postings := range idx.Postings {
+		terms = append(terms, term)
+		df[term] = len(postings)
+	}
+	sort.Strings(terms)
+	v := &Vocabulary{
+		terms:    terms,
+		df:       df,
+		docs:     make(map[int]Surfaces),
+		counts:   make(map[string]map[string]int),
+		surfaces: make(map[string][]string),
+	}
+	v.setSurfaces(surfaces)
+	return v
+}
+
+func (v *Vocabulary) Len() int {
+	return len(v.terms)
+}
+
+// Update refreshes the frequencies of the given terms from idx, adding the
+// new terms and dropping those gone from it, and replaces the surfaces of
+// the given comics; a nil entry drops those of a comic.
+func (v *Vocabulary) Update(idx *Index, changed map[string]struct{}, surfaces map[int]Surfaces) {
+	v.setSurfaces(surfaces)
+	var added []string
+	removed := false
+	for term := range changed {
+		n := len(idx.Postings[term])
+		_, known := v.df[term]
+		switch {
+		case n == 0 && known:
+			delete(v.df, term)
+			removed = true
+		case n > 0 && !known:
+			added = append(added, term)
+		}
+		if n > 0 {
+			v.df[term] = n
+		}
+	}
+	if removed {
+		kept := v.terms[:0]
+		for _, term := range v.terms {
+			if _, ok := v.df[term]; ok {
+				kept = append(kept, term)
+			}
+		}
+		v.terms = kept
+	}
+	if len(added) > 0 {
+		sort.Strings(added)
+		terms := make([]string, 0, len(v.terms)+len(added))
+		i, j := 0, 0
+		for i < len(v.terms) || j < len(added) {
+			if j == len(added) || i < len(v.terms) && v.terms[i] < added[j] {
+				terms = append(terms, v.terms[i])
+				i++
+			} else {
+				terms = append(terms, added[j])
+				j++
+			}
+		}
+		v.terms = terms
+	}
+}
+
+func (v *Vocabulary) setSurfaces(surfaces map[int]Surfaces) {
+	touched := make(map[string]struct{})
+	for id, stems := range surfaces {
+		for stem, words := range v.docs[id] {
+			for _, w := range words {
+				if v.counts[stem][w]--; v.counts[stem][w] == 0 {
+					delete(v.counts[stem], w)
+				}
+			}
+			touched[stem] = struct{}{}
+		}
+		delete(v.docs, id)
+		if stems == nil {
+			continue
+		}
+		v.docs[id] = stems
+		for stem, words := range stems {
+			if v.counts[stem] == nil {
+				v.counts[stem] = make(map[string]int)
+			}
+			for _, w := range words {
+				v.counts[stem][w]++
+			}
+			touched[stem] = struct{}{}
+		}
+	}
+
+	for stem := range touched {
+		counts := v.counts[stem]
+		if len(counts) == 0 {
+			delete(v.counts, stem)
+			delete(v.surfaces, stem)
+			continue
+		}
+		words := make([]string, 0, len(counts))
+		for w := range counts {
+			words = append(words, w)
+		}
+		sort.Slice(words, func(i, j int) bool {
+			ni, nj := counts[words[i]], counts[words[j]]
+			return ni > nj || ni == nj && words[i] < words[j]
+		})
+		v.surfaces[stem] = words
+	}
+}
+
+// Complete returns up to n terms starting with prefix, most frequent first.
+func (v *Vocabulary) Complete(prefix string, n int) []Completion {
+	lo := sort.SearchStrings(v.terms, prefix)
+	hi := lo + sort.Search(len(v.terms)-lo, func(i int) bool {
+		return !strings.HasPrefix(v.terms[lo+i], prefix)
+	})
+	out := make([]Completion, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		out = append(out, Completion{Term: v.terms[i], Count: v.df[v.terms[i]]})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// stemsOf returns the terms prefix starts with but is longer than, like the
+// stem of a word typed in full.
+func (v *Vocabulary) stemsOf(prefix string) []Completion {
+	var out []Completion
+	for i := len(prefix) - 1; i > 0; i-- {
+		if n, ok := v.df[prefix[:i]]; ok {
+			out = append(out, Completion{Term: prefix[:i], Count: n})
+		}
+	}
+	return out
+}
+
+// completions turns the stems found for prefix, most frequent first, into
+// up to n words: for each stem, its most frequent word starting with prefix.
+func (v *Vocabulary) completions(prefix string, stems []Completion, n int) []Completion {
+	out := make([]Completion, 0, n)
+	seen := make(map[string]struct{})
+	for _, c := range stems {
+		surfaces, ok := v.surfaces[c.Term]
+		if !ok {
+			surfaces = []string{c.Term}
+		}
+		i := slices.IndexFunc(surfaces, func(w string) bool { return strings.HasPrefix(w, prefix) })
+		if i < 0 {
+			continue
+		}
+		if _, ok := seen[surfaces[i]]; ok {
+			continue
+		}
+		seen[surfaces[i]] = struct{}{}
+		out = append(out, Completion{Term: surfaces[i], Count: c.Count})
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
+
+// surfacesOf finds the words the stems of the comics stand for in their
+// texts. Comics whose texts cannot be analyzed get no surfaces, so their
+// stems complete as they are.
+func (s *Service) surfacesOf(ctx context.Context, ids []int) map[int]Surfaces {
+	out := make(map[int]Surfaces, len(ids))
+	for _, id := range ids {
+		out[id] = Surfaces{}
+	}
+	for chunk := range slices.Chunk(ids, maxSurfaceComics) {
+		if err := s.findSurfaces(ctx, chunk, out); err != nil {
+			s.log.Warn("cannot find the words of stems", "comics", len(chunk), "error", err)
+		}
+	}
+	return out
+}
+
+func (s *Service) findSurfaces(ctx context.Context, ids []int, out map[int]Surfaces) error {
+	texts, err := s.store.GetComicTexts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	var batch []string
+	var owners []int
+	for id, text := range texts {
+		for _, t := range []string{text.Title, text.Alt, text.Transcript} {
+			if strings.TrimSpace(t) != "" && len(t) <= maxDocumentLen {
+				batch = append(batch, t)
+				owners = append(owners, id)
+			}
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	words, err := s.words.AnalyzeBatch(ctx, batch)
+	if err == nil && len(words) != len(batch) {
+		err = fmt.Errorf("got words of %d texts out of %d", len(words), len(batch))
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, ws := range words {
+		stems := out[owners[i]]
+		for _, w := range ws {
+			if w.Stem == "" {
+				continue
+			}
+			surface := strings.ToLower(batch[i][w.Start:w.End])
+			if !slices.Contains(stems[w.Stem], surface) {
+				stems[w.Stem] = append(stems[w.Stem], surface)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVocabularyComplete(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("program", "python"),
+		2: tokens("program", "progress"),
+		3: tokens("program", "progress", "prolog"),
+		4: tokens("pyth"),
+	})
+	v := NewVocabulary(idx, nil)
+
+	got := v.Complete("pro", 10)
+	want := []Completion{{"program", 3}, {"progress", 2}, {"prolog", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete(pro) = %v, want %v", got, want)
+	}
+
+	got = v.Complete("pro", 2)
+	if !reflect.DeepEqual(got, want[:2]) {
+		t.Fatalf("Complete(pro, 2) = %v, want %v", got, want[:2])
+	}
+
+	got = v.Complete("pyth", 10)
+	want = []Completion{{"pyth", 1}, {"python", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete(pyth) = %v, want %v", got, want)
+	}
+
+	if got := v.Complete("zz", 10); len(got) != 0 {
+		t.Fatalf("Complete(zz) = %v, want none", got)
+	}
+}
+
+func TestVocabularyUpdate(t *testing.T) {
+	idx := BuildIndex(map[int][]Token{
+		1: tokens("program", "python"),
+		2: tokens("program", "progress"),
+	})
+	v := NewVocabulary(idx, map[int]Surfaces{
+		1: {"program": {"programs"}, "python": {"python"}},
+		2: {"program": {"program"}, "progress": {"progress"}},
+	})
+
+	v.Update(idx, idx.Merge(map[int][]Token{
+		1: nil,
+		3: tokens("prolog", "program"),
+	}), map[int]Surfaces{
+		1: nil,
+		3: {"prolog": {"prolog"}, "program": {"programming", "program"}},
+	})
+	want := NewVocabulary(idx, map[int]Surfaces{
+		2: {"program": {"program"}, "progress": {"progress"}},
+		3: {"prolog": {"prolog"}, "program": {"programming", "program"}},
+	})
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("Update gave %+v, want %+v", v, want)
+	}
+}
+
+// textStore serves the texts of comics.
+type textStore struct {
+	Storage
+	texts map[int]ComicText
+}
+
+func (s textStore) GetComicTexts(_ context.Context, ids []int) (map[int]ComicText, error) {
+	out := make(map[int]ComicText, len(ids))
+	for _, id := range ids {
+		if text, ok := s.texts[id]; ok {
+			out[id] = text
+		}
+	}
+	return out, nil
+}
+
+// stemmingWords stems words by cutting them to six letters.
+type stemmingWords struct {
+	expandingWords
+}
+
+func (stemmingWords) AnalyzeBatch(_ context.Context, texts []string) ([][]Word, error) {
+	out := make([][]Word, len(texts))
+	for i, text := range texts {
+		for _, span := range testWordRe.FindAllStringIndex(text, -1) {
+			stem := strings.ToLower(text[span[0]:span[1]])
+			out[i] = append(out[i], Word{Stem: stem[:min(len(stem), 6)], Start: span[0], End: span[1]})
+		}
+	}
+	return out, nil
+}
+
+func TestService_Suggest(t *testing.T) {
+	texts := map[int]ComicText{
+		1: {Title: "Physics", Alt: "Physics is physical."},
+		2: {Transcript: "PHYSICS, says the physicist"},
+	}
+	s := &Service{
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		words: stemmingWords{},
+		store: textStore{texts: texts},
+		index: NewIndex(),
+		vocab: NewVocabulary(NewIndex(), nil),
+	}
+	ctx := context.Background()
+	suggest := func(want map[string][]Completion) {
+		t.Helper()
+		for prefix, want := range want {
+			got, err := s.Suggest(ctx, prefix, 10)
+			if err != nil {
+				t.Fatalf("Suggest(%s) failed: %v", prefix, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Suggest(%s) = %v, want %v", prefix, got, want)
+			}
+		}
+	}
+
+	s.merge(ctx, map[int][]Token{1: tokens("physic", "physic"), 2: tokens("physic")}, nil, time.Time{})
+	suggest(map[string][]Completion{
+		"phy":       {{"physics", 2}},
+		"physics":   {{"physics", 2}},
+		"physical":  {{"physical", 2}},
+		"physicist": {{"physicist", 2}},
+		"chemistry": {},
+	})
+
+	texts[1] = ComicText{Alt: "A physicist"}
+	s.merge(ctx, map[int][]Token{1: tokens("a", "physic")}, nil, time.Time{})
+	suggest(map[string][]Completion{
+		"phy":      {{"physicist", 2}},
+		"physical": {},
+	})
+}
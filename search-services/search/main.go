@@ -17,6 +17,7 @@ import (
 	searchpb "yadro.com/course/proto/search"
 	searchdb "yadro.com/course/search/adapters/db"
 	searchgrpc "yadro.com/course/search/adapters/grpc"
+	"yadro.com/course/search/adapters/snapshot"
 	searchwords "yadro.com/course/search/adapters/words"
 	"yadro.com/course/search/config"
 	"yadro.com/course/search/core"
@@ -51,13 +52,20 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 
 	// Words adapter
-	wordsClient, err := searchwords.NewClient(cfg.WordsAddress, log)
+	wordsClient, err := searchwords.NewClient(cfg.WordsAddress, cfg.WordsProfile, log)
 	if err != nil {
 		return fmt.Errorf("failed to create words client: %w", err)
 	}
 
+	// Index snapshot adapter
+	snapshots, err := snapshot.New(log, cfg.IndexSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot store: %w", err)
+	}
+
 	// Core service
-	svc, err := core.NewService(log, store, wordsClient)
+	boosts := core.FieldBoosts{Title: cfg.TitleBoost, Alt: cfg.AltBoost, Transcript: cfg.TranscriptBoost}
+	svc, err := core.NewService(log, store, wordsClient, snapshots, boosts)
 	if err != nil {
 		return fmt.Errorf("failed to create search service: %w", err)
 	}
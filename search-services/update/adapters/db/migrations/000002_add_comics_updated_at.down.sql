@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS comics_updated_at_idx;
+
+ALTER TABLE comics DROP COLUMN IF EXISTS updated_at;
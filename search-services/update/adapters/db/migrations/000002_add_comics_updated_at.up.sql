@@ -0,0 +1,4 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS comics_updated_at_idx ON comics (updated_at);
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS positions INTEGER[] NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE comics DROP COLUMN IF EXISTS fields;
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS fields SMALLINT[] NOT NULL DEFAULT '{}';
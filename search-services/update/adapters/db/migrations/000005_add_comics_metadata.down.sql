@@ -0,0 +1,7 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS fetched_at,
+    DROP COLUMN IF EXISTS page_url,
+    DROP COLUMN IF EXISTS published,
+    DROP COLUMN IF EXISTS transcript,
+    DROP COLUMN IF EXISTS alt,
+    DROP COLUMN IF EXISTS title;
@@ -0,0 +1,7 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS title      TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS alt        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS transcript TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS published  DATE,
+    ADD COLUMN IF NOT EXISTS page_url   TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS fetched_at TIMESTAMPTZ;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS update_jobs (
+    id           BIGSERIAL   PRIMARY KEY,
+    trigger      TEXT        NOT NULL,
+    state        TEXT        NOT NULL,
+    total        INTEGER     NOT NULL DEFAULT 0,
+    done         INTEGER     NOT NULL DEFAULT 0,
+    failed       INTEGER     NOT NULL DEFAULT 0,
+    placeholders INTEGER     NOT NULL DEFAULT 0,
+    errors       TEXT[]      NOT NULL DEFAULT '{}',
+    started_at   TIMESTAMPTZ NOT NULL,
+    finished_at  TIMESTAMPTZ
+);
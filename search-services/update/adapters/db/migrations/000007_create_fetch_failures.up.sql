@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS fetch_failures (
+    id              INTEGER     PRIMARY KEY,
+    class           TEXT        NOT NULL,
+    error           TEXT        NOT NULL,
+    attempts        INTEGER     NOT NULL,
+    first_failed_at TIMESTAMPTZ NOT NULL,
+    last_failed_at  TIMESTAMPTZ NOT NULL,
+    next_retry_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS fetch_failures_next_retry_at_idx ON fetch_failures (next_retry_at);
@@ -0,0 +1,4 @@
+DELETE FROM comics WHERE status = 'failed';
+UPDATE comics SET img_url = 'missing' WHERE status = 'not_found';
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS status;
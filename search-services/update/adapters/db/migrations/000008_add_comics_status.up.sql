@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'ok';
+UPDATE comics SET status = 'not_found', img_url = '' WHERE img_url = 'missing';
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN IF NOT EXISTS norm_version TEXT NOT NULL DEFAULT '';
@@ -2,10 +2,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"log/slog"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"yadro.com/course/update/core"
 )
 
@@ -24,53 +28,326 @@ func New(log *slog.Logger, address string) (*DB, error) {
 }
 
 func (db *DB) Add(ctx context.Context, comics core.Comics) error {
-	words := comics.Words
-	if words == nil {
-		words = []string{}
+	status := comics.Status
+	if status == "" {
+		status = core.ComicOK
 	}
-
+	words, positions, fields := tokenArrays(comics)
 	_, err := db.conn.ExecContext(
 		ctx,
-		`INSERT INTO comics (id, img_url, words)
-         VALUES ($1, $2, $3::text[])
-         ON CONFLICT (id) DO NOTHING`,
+		`INSERT INTO comics (id, status, img_url, title, alt, transcript, published, page_url, fetched_at,
+                             words, positions, fields, norm_version)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now(), $9::text[], $10::integer[], $11::smallint[], $12)
+         ON CONFLICT (id) DO UPDATE
+         SET status = EXCLUDED.status, img_url = EXCLUDED.img_url, title = EXCLUDED.title,
+             alt = EXCLUDED.alt, transcript = EXCLUDED.transcript, published = EXCLUDED.published,
+             page_url = EXCLUDED.page_url, fetched_at = EXCLUDED.fetched_at,
+             words = EXCLUDED.words, positions = EXCLUDED.positions, fields = EXCLUDED.fields,
+             norm_version = EXCLUDED.norm_version, updated_at = now()
+         WHERE EXCLUDED.status <> 'failed' OR comics.status = 'failed'`,
 		comics.ID,
+		status,
 		comics.URL,
+		comics.Title,
+		comics.Alt,
+		comics.Transcript,
+		sql.NullTime{Time: comics.Published, Valid: !comics.Published.IsZero()},
+		comics.PageURL,
 		words,
+		positions,
+		fields,
+		comics.NormVersion,
+	)
+	return err
+}
+
+func (db *DB) UpdateWords(ctx context.Context, comics core.Comics) error {
+	words, positions, fields := tokenArrays(comics)
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE comics
+         SET words = $2::text[], positions = $3::integer[], fields = $4::smallint[],
+             norm_version = $5, updated_at = now()
+         WHERE id = $1 AND status = $6`,
+		comics.ID, words, positions, fields, comics.NormVersion, core.ComicOK,
 	)
 	return err
 }
 
+func tokenArrays(comics core.Comics) (pq.StringArray, pq.Int64Array, pq.Int64Array) {
+	words := comics.Words
+	if words == nil {
+		words = []string{}
+	}
+	positions := make(pq.Int64Array, 0, len(comics.Positions))
+	for _, p := range comics.Positions {
+		positions = append(positions, int64(p))
+	}
+	fields := make(pq.Int64Array, 0, len(comics.Fields))
+	for _, f := range comics.Fields {
+		fields = append(fields, int64(f))
+	}
+	return words, positions, fields
+}
+
 func (db *DB) Stats(ctx context.Context) (core.DBStats, error) {
 	var st core.DBStats
 	if err := db.conn.GetContext(ctx, &st.WordsTotal,
-		`SELECT COALESCE(SUM(array_length(words, 1)), 0) FROM comics`,
+		`SELECT COALESCE(SUM(array_length(words, 1)), 0) FROM comics WHERE status = $1`, core.ComicOK,
 	); err != nil {
 		return core.DBStats{}, err
 	}
 	if err := db.conn.GetContext(ctx, &st.WordsUnique,
 		`SELECT COALESCE(COUNT(DISTINCT w), 0)
-         FROM comics, UNNEST(words) AS w`,
+         FROM comics, UNNEST(words) AS w
+         WHERE status = $1`, core.ComicOK,
 	); err != nil {
 		return core.DBStats{}, err
 	}
-	if err := db.conn.GetContext(ctx, &st.ComicsFetched,
-		`SELECT COUNT(*) FROM comics`,
+	var counts []struct {
+		Status core.ComicStatus `db:"status"`
+		Count  int              `db:"count"`
+	}
+	if err := db.conn.SelectContext(ctx, &counts,
+		`SELECT status, COUNT(*) AS count FROM comics GROUP BY status`,
 	); err != nil {
 		return core.DBStats{}, err
 	}
+	for _, c := range counts {
+		switch c.Status {
+		case core.ComicOK:
+			st.ComicsFetched = c.Count
+		case core.ComicNotFound:
+			st.ComicsNotFound = c.Count
+		case core.ComicFailed:
+			st.ComicsFailed = c.Count
+		}
+	}
 	return st, nil
 }
 
 func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	var ids []int
-	if err := db.conn.SelectContext(ctx, &ids, `SELECT id FROM comics ORDER BY id`); err != nil {
+	if err := db.conn.SelectContext(ctx, &ids,
+		`SELECT id FROM comics WHERE status <> $1 ORDER BY id`, core.ComicFailed,
+	); err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
+func (db *DB) PlaceholderIDs(ctx context.Context) ([]int, error) {
+	var ids []int
+	if err := db.conn.SelectContext(ctx, &ids,
+		`SELECT id FROM comics WHERE status = $1 ORDER BY id`, core.ComicNotFound,
+	); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// EmptyIDs lists the comics fetched without words.
+func (db *DB) EmptyIDs(ctx context.Context) ([]int, error) {
+	var ids []int
+	if err := db.conn.SelectContext(ctx, &ids,
+		`SELECT id FROM comics WHERE status = $1 AND cardinality(words) = 0 ORDER BY id`,
+		core.ComicOK,
+	); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// StaleComics lists the comics normalized with another version than the
+// given one. Comics stored before their texts were kept have nothing to be
+// normalized again from and are left out.
+func (db *DB) StaleComics(ctx context.Context, version string, after, limit int) ([]core.Comics, error) {
+	var rows []struct {
+		ID         int    `db:"id"`
+		Title      string `db:"title"`
+		Alt        string `db:"alt"`
+		Transcript string `db:"transcript"`
+	}
+	if err := db.conn.SelectContext(ctx, &rows,
+		`SELECT id, title, alt, transcript FROM comics
+         WHERE status = $1 AND norm_version <> $2 AND id > $3
+           AND (title <> '' OR alt <> '' OR transcript <> '')
+         ORDER BY id LIMIT $4`,
+		core.ComicOK, version, after, limit,
+	); err != nil {
+		return nil, err
+	}
+	comics := make([]core.Comics, 0, len(rows))
+	for _, r := range rows {
+		comics = append(comics, core.Comics{
+			ID: r.ID, Status: core.ComicOK, Title: r.Title, Alt: r.Alt, Transcript: r.Transcript,
+		})
+	}
+	return comics, nil
+}
+
+func (db *DB) CountStale(ctx context.Context, version string) (int, error) {
+	var n int
+	err := db.conn.GetContext(ctx, &n,
+		`SELECT COUNT(*) FROM comics WHERE status = $1 AND norm_version <> $2
+           AND (title <> '' OR alt <> '' OR transcript <> '')`,
+		core.ComicOK, version,
+	)
+	return n, err
+}
+
 func (db *DB) Drop(ctx context.Context) error {
-	_, err := db.conn.ExecContext(ctx, `TRUNCATE TABLE comics`)
+	_, err := db.conn.ExecContext(ctx, `TRUNCATE TABLE comics, fetch_failures`)
 	return err
 }
+
+type jobRow struct {
+	ID           int64          `db:"id"`
+	Trigger      string         `db:"trigger"`
+	State        string         `db:"state"`
+	Total        int            `db:"total"`
+	Done         int            `db:"done"`
+	Failed       int            `db:"failed"`
+	Placeholders int            `db:"placeholders"`
+	Errors       pq.StringArray `db:"errors"`
+	StartedAt    time.Time      `db:"started_at"`
+	FinishedAt   sql.NullTime   `db:"finished_at"`
+}
+
+func (r jobRow) job() core.Job {
+	return core.Job{
+		ID:           r.ID,
+		Trigger:      core.JobTrigger(r.Trigger),
+		State:        core.JobState(r.State),
+		Total:        r.Total,
+		Done:         r.Done,
+		Failed:       r.Failed,
+		Placeholders: r.Placeholders,
+		Errors:       r.Errors,
+		StartedAt:    r.StartedAt,
+		FinishedAt:   r.FinishedAt.Time,
+	}
+}
+
+const jobColumns = `id, trigger, state, total, done, failed, placeholders, errors, started_at, finished_at`
+
+func (db *DB) CreateJob(ctx context.Context, job core.Job) (int64, error) {
+	var id int64
+	err := db.conn.GetContext(ctx, &id,
+		`INSERT INTO update_jobs (trigger, state, started_at) VALUES ($1, $2, $3) RETURNING id`,
+		job.Trigger, job.State, job.StartedAt,
+	)
+	return id, err
+}
+
+func (db *DB) FinishJob(ctx context.Context, job core.Job) error {
+	errs := job.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE update_jobs
+         SET state = $2, total = $3, done = $4, failed = $5, placeholders = $6,
+             errors = $7::text[], finished_at = $8
+         WHERE id = $1`,
+		job.ID, job.State, job.Total, job.Done, job.Failed, job.Placeholders,
+		pq.StringArray(errs),
+		sql.NullTime{Time: job.FinishedAt, Valid: !job.FinishedAt.IsZero()},
+	)
+	return err
+}
+
+func (db *DB) Job(ctx context.Context, id int64) (core.Job, error) {
+	var row jobRow
+	err := db.conn.GetContext(ctx, &row, `SELECT `+jobColumns+` FROM update_jobs WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Job{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Job{}, err
+	}
+	return row.job(), nil
+}
+
+func (db *DB) Jobs(ctx context.Context, limit int) ([]core.Job, error) {
+	var rows []jobRow
+	if err := db.conn.SelectContext(ctx, &rows,
+		`SELECT `+jobColumns+` FROM update_jobs ORDER BY id DESC LIMIT $1`, limit,
+	); err != nil {
+		return nil, err
+	}
+	jobs := make([]core.Job, 0, len(rows))
+	for _, r := range rows {
+		jobs = append(jobs, r.job())
+	}
+	return jobs, nil
+}
+
+func (db *DB) InterruptJobs(ctx context.Context, reason string) ([]core.Job, error) {
+	var rows []jobRow
+	if err := db.conn.SelectContext(ctx, &rows,
+		`UPDATE update_jobs
+         SET state = $1, errors = array_append(errors, $2), finished_at = now()
+         WHERE state = $3
+         RETURNING `+jobColumns,
+		core.JobFailed, reason, core.JobRunning,
+	); err != nil {
+		return nil, err
+	}
+	jobs := make([]core.Job, 0, len(rows))
+	for _, r := range rows {
+		jobs = append(jobs, r.job())
+	}
+	return jobs, nil
+}
+
+func (db *DB) SaveFailure(ctx context.Context, f core.Failure) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO fetch_failures (id, class, error, attempts, first_failed_at, last_failed_at, next_retry_at)
+         VALUES ($1, $2, $3, $4, $5, $5, $6)
+         ON CONFLICT (id) DO UPDATE
+         SET class = EXCLUDED.class, error = EXCLUDED.error, attempts = EXCLUDED.attempts,
+             last_failed_at = EXCLUDED.last_failed_at, next_retry_at = EXCLUDED.next_retry_at`,
+		f.ID, f.Class, f.Error, f.Attempts, f.LastFailedAt, f.NextRetryAt,
+	)
+	return err
+}
+
+func (db *DB) ClearFailures(ctx context.Context, ids []int) error {
+	arr := make(pq.Int64Array, 0, len(ids))
+	for _, id := range ids {
+		arr = append(arr, int64(id))
+	}
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM fetch_failures WHERE id = ANY($1::integer[])`, arr)
+	return err
+}
+
+func (db *DB) Failures(ctx context.Context, limit int) ([]core.Failure, error) {
+	var rows []struct {
+		ID            int       `db:"id"`
+		Class         string    `db:"class"`
+		Error         string    `db:"error"`
+		Attempts      int       `db:"attempts"`
+		FirstFailedAt time.Time `db:"first_failed_at"`
+		LastFailedAt  time.Time `db:"last_failed_at"`
+		NextRetryAt   time.Time `db:"next_retry_at"`
+	}
+	if err := db.conn.SelectContext(ctx, &rows,
+		`SELECT id, class, error, attempts, first_failed_at, last_failed_at, next_retry_at
+         FROM fetch_failures ORDER BY next_retry_at, id LIMIT NULLIF($1, 0)`, limit,
+	); err != nil {
+		return nil, err
+	}
+	failures := make([]core.Failure, 0, len(rows))
+	for _, r := range rows {
+		failures = append(failures, core.Failure{
+			ID:            r.ID,
+			Class:         core.FailureClass(r.Class),
+			Error:         r.Error,
+			Attempts:      r.Attempts,
+			FirstFailedAt: r.FirstFailedAt,
+			LastFailedAt:  r.LastFailedAt,
+			NextRetryAt:   r.NextRetryAt,
+		})
+	}
+	return failures, nil
+}
@@ -1,11 +1,19 @@
 package events
 
 import (
+	"encoding/json"
 	"log/slog"
 
 	"github.com/nats-io/nats.go"
 )
 
+const subjectDBUpdated = "xkcd.db.updated"
+
+type DBUpdatedEvent struct {
+	IDs       []int `json:"ids,omitempty"`
+	Reconcile bool  `json:"reconcile,omitempty"`
+}
+
 type Publisher struct {
 	log *slog.Logger
 	nc  *nats.Conn
@@ -26,11 +34,24 @@ func (p *Publisher) Close() {
 	}
 }
 
-func (p *Publisher) PublishDBUpdated() {
+func (p *Publisher) PublishDBUpdated(ids []int) {
+	p.publish(DBUpdatedEvent{IDs: ids})
+}
+
+func (p *Publisher) PublishDBReconcile() {
+	p.publish(DBUpdatedEvent{Reconcile: true})
+}
+
+func (p *Publisher) publish(event DBUpdatedEvent) {
 	if p.nc == nil {
 		return
 	}
-	if err := p.nc.Publish("xkcd.db.updated", nil); err != nil {
+	data, err := json.Marshal(event)
+	if err != nil {
+		p.log.Error("failed to marshal db updated event", "error", err)
+		return
+	}
+	if err := p.nc.Publish(subjectDBUpdated, data); err != nil {
 		p.log.Error("failed to publish db updated", "error", err)
 		return
 	}
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"log/slog"
 	"os"
 	"testing"
@@ -71,11 +72,33 @@ func TestPublisher_PublishDBUpdated(t *testing.T) {
 		t.Fatalf("failed to flush subscription: %v", err)
 	}
 
-	p.PublishDBUpdated()
+	p.PublishDBUpdated([]int{1, 2, 3})
 
 	select {
-	case <-msgCh:
+	case msg := <-msgCh:
+		var event DBUpdatedEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		if len(event.IDs) != 3 || event.Reconcile {
+			t.Fatalf("unexpected event: %+v", event)
+		}
 	case <-time.After(5 * time.Second):
 		t.Fatalf("did not receive db updated message")
 	}
+
+	p.PublishDBReconcile()
+
+	select {
+	case msg := <-msgCh:
+		var event DBUpdatedEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		if !event.Reconcile {
+			t.Fatalf("expected reconcile event, got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("did not receive db reconcile message")
+	}
 }
@@ -3,10 +3,12 @@ package grpc
 import (
 	"context"
 	"errors"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/core"
 )
@@ -25,9 +27,22 @@ func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 }
 
 func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatusReply, error) {
-	st := s.service.Status(ctx)
+	return s.statusReply(ctx, s.service.Progress(ctx)), nil
+}
+
+func (s *Server) WatchStatus(_ *emptypb.Empty, stream updatepb.Update_WatchStatusServer) error {
+	ctx := stream.Context()
+	for p := range s.service.WatchProgress(ctx) {
+		if err := stream.Send(s.statusReply(ctx, p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) statusReply(ctx context.Context, p core.Progress) *updatepb.StatusReply {
 	var pb updatepb.Status
-	switch st {
+	switch p.Status {
 	case core.StatusIdle:
 		pb = updatepb.Status_STATUS_IDLE
 	case core.StatusRunning:
@@ -35,17 +50,167 @@ func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.Status
 	default:
 		pb = updatepb.Status_STATUS_UNSPECIFIED
 	}
-	return &updatepb.StatusReply{Status: pb}, nil
+	reply := &updatepb.StatusReply{Status: pb}
+	if next := s.service.NextRun(ctx); !next.IsZero() {
+		reply.NextRun = timestamppb.New(next)
+	}
+	if !p.StartedAt.IsZero() {
+		reply.Progress = &updatepb.Progress{
+			JobId:        p.ID,
+			Total:        int64(p.Total),
+			Done:         int64(p.Done),
+			Failed:       int64(p.Failed),
+			Placeholders: int64(p.Placeholders),
+			StartedAt:    timestamppb.New(p.StartedAt),
+			FinishedAt:   timestampOrNil(p.FinishedAt),
+			Eta:          timestampOrNil(p.ETA),
+		}
+	}
+	return reply
+}
+
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
 
-func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
-	if err := s.service.Update(ctx); err != nil {
+func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*updatepb.UpdateReply, error) {
+	job, err := s.service.Update(ctx)
+	if err != nil {
 		if errors.Is(err, core.ErrAlreadyExists) {
-			return nil, status.Error(codes.AlreadyExists, "update already running")
+			return &updatepb.UpdateReply{Job: toJob(job), AlreadyRunning: true}, nil
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
-	return &emptypb.Empty{}, nil
+	return &updatepb.UpdateReply{Job: toJob(job)}, nil
+}
+
+func (s *Server) Reindex(ctx context.Context, _ *emptypb.Empty) (*updatepb.UpdateReply, error) {
+	job, err := s.service.Reindex(ctx)
+	if err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			return &updatepb.UpdateReply{Job: toJob(job), AlreadyRunning: true}, nil
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &updatepb.UpdateReply{Job: toJob(job)}, nil
+}
+
+func (s *Server) Refetch(ctx context.Context, in *updatepb.RefetchRequest) (*updatepb.UpdateReply, error) {
+	r := core.Refetch{
+		IDs:          make([]int, 0, len(in.GetIds())),
+		Ranges:       make([]core.IDRange, 0, len(in.GetRanges())),
+		Placeholders: in.GetPlaceholders(),
+		EmptyWords:   in.GetEmptyWords(),
+	}
+	for _, id := range in.GetIds() {
+		r.IDs = append(r.IDs, int(id))
+	}
+	for _, rng := range in.GetRanges() {
+		r.Ranges = append(r.Ranges, core.IDRange{From: int(rng.GetFrom()), To: int(rng.GetTo())})
+	}
+	job, err := s.service.Refetch(ctx, r)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrAlreadyExists):
+			return &updatepb.UpdateReply{Job: toJob(job), AlreadyRunning: true}, nil
+		case errors.Is(err, core.ErrBadArguments):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &updatepb.UpdateReply{Job: toJob(job)}, nil
+}
+
+func (s *Server) Cancel(ctx context.Context, _ *emptypb.Empty) (*updatepb.Job, error) {
+	job, err := s.service.Cancel(ctx)
+	if err != nil {
+		if errors.Is(err, core.ErrNotRunning) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toJob(job), nil
+}
+
+func (s *Server) GetJob(ctx context.Context, in *updatepb.GetJobRequest) (*updatepb.Job, error) {
+	job, err := s.service.Job(ctx, in.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrBadArguments):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, core.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "job not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toJob(job), nil
+}
+
+func (s *Server) ListJobs(ctx context.Context, in *updatepb.ListJobsRequest) (*updatepb.ListJobsReply, error) {
+	jobs, err := s.service.Jobs(ctx, int(in.GetLimit()))
+	if err != nil {
+		if errors.Is(err, core.ErrBadArguments) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	reply := &updatepb.ListJobsReply{Jobs: make([]*updatepb.Job, 0, len(jobs))}
+	for _, job := range jobs {
+		reply.Jobs = append(reply.Jobs, toJob(job))
+	}
+	return reply, nil
+}
+
+func (s *Server) ListFailures(ctx context.Context, in *updatepb.ListFailuresRequest) (*updatepb.ListFailuresReply, error) {
+	failures, err := s.service.Failures(ctx, int(in.GetLimit()))
+	if err != nil {
+		if errors.Is(err, core.ErrBadArguments) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	reply := &updatepb.ListFailuresReply{Failures: make([]*updatepb.Failure, 0, len(failures))}
+	for _, f := range failures {
+		reply.Failures = append(reply.Failures, &updatepb.Failure{
+			Id:            int64(f.ID),
+			Class:         string(f.Class),
+			Error:         f.Error,
+			Attempts:      int32(f.Attempts),
+			FirstFailedAt: timestampOrNil(f.FirstFailedAt),
+			LastFailedAt:  timestampOrNil(f.LastFailedAt),
+			NextRetryAt:   timestampOrNil(f.NextRetryAt),
+		})
+	}
+	return reply, nil
+}
+
+func toJob(job core.Job) *updatepb.Job {
+	var state updatepb.JobState
+	switch job.State {
+	case core.JobRunning:
+		state = updatepb.JobState_JOB_STATE_RUNNING
+	case core.JobSucceeded:
+		state = updatepb.JobState_JOB_STATE_SUCCEEDED
+	case core.JobFailed:
+		state = updatepb.JobState_JOB_STATE_FAILED
+	case core.JobCancelled:
+		state = updatepb.JobState_JOB_STATE_CANCELLED
+	}
+	return &updatepb.Job{
+		Id:           job.ID,
+		State:        state,
+		Trigger:      string(job.Trigger),
+		Total:        int64(job.Total),
+		Done:         int64(job.Done),
+		Failed:       int64(job.Failed),
+		Placeholders: int64(job.Placeholders),
+		Errors:       job.Errors,
+		StartedAt:    timestampOrNil(job.StartedAt),
+		FinishedAt:   timestampOrNil(job.FinishedAt),
+	}
 }
 
 func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsReply, error) {
@@ -54,10 +219,12 @@ func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsRe
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &updatepb.StatsReply{
-		WordsTotal:    int64(st.WordsTotal),
-		WordsUnique:   int64(st.WordsUnique),
-		ComicsTotal:   int64(st.ComicsTotal),
-		ComicsFetched: int64(st.ComicsFetched),
+		WordsTotal:     int64(st.WordsTotal),
+		WordsUnique:    int64(st.WordsUnique),
+		ComicsTotal:    int64(st.ComicsTotal),
+		ComicsFetched:  int64(st.ComicsFetched),
+		ComicsNotFound: int64(st.ComicsNotFound),
+		ComicsFailed:   int64(st.ComicsFailed),
 	}, nil
 }
 
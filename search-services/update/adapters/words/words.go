@@ -2,6 +2,8 @@ package words
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 
 	"google.golang.org/grpc"
@@ -14,41 +16,114 @@ import (
 	"yadro.com/course/update/core"
 )
 
+// maxBatchSize mirrors the batch limit of the words service.
+const maxBatchSize = 1000
+
 type Client struct {
-	log    *slog.Logger
-	client wordspb.WordsClient
+	log     *slog.Logger
+	client  wordspb.WordsClient
+	profile string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+func NewClient(address, profile string, log *slog.Logger) (*Client, error) {
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		log:    log,
+		client:  wordspb.NewWordsClient(conn),
+		profile: profile,
+		log:     log,
 	}, nil
 }
 
-func (c Client) Norm(ctx context.Context, phrase string) ([]string, error) {
-	if phrase == "" {
-		return nil, core.ErrBadArguments
+// NormBatch normalizes phrases in one NormBatch call, or over a NormStream
+// when there are more of them than fit in a batch.
+func (c Client) NormBatch(ctx context.Context, phrases []string) ([][]core.Token, error) {
+	if len(phrases) > maxBatchSize {
+		return c.normStream(ctx, phrases)
+	}
+
+	req := &wordspb.BatchRequest{Phrases: make([]*wordspb.WordsRequest, 0, len(phrases))}
+	for _, p := range phrases {
+		req.Phrases = append(req.Phrases, &wordspb.WordsRequest{Phrase: p, Profile: c.profile})
 	}
-	resp, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	resp, err := c.client.NormBatch(ctx, req)
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			switch s.Code() {
-			case codes.ResourceExhausted:
-				return nil, core.ErrRequestTooLarge
-			case codes.InvalidArgument:
-				return nil, core.ErrBadArguments
-			default:
-				return nil, err
+		return nil, mapError(err)
+	}
+	out := make([][]core.Token, 0, len(resp.GetReplies()))
+	for _, reply := range resp.GetReplies() {
+		out = append(out, toTokens(reply))
+	}
+	return out, nil
+}
+
+func (c Client) normStream(ctx context.Context, phrases []string) ([][]core.Token, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.NormStream(ctx)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	sent := make(chan error, 1)
+	go func() {
+		for _, p := range phrases {
+			if err := stream.Send(&wordspb.WordsRequest{Phrase: p, Profile: c.profile}); err != nil {
+				sent <- err
+				return
 			}
 		}
+		sent <- stream.CloseSend()
+	}()
+
+	out := make([][]core.Token, 0, len(phrases))
+	for range phrases {
+		reply, err := stream.Recv()
+		if err != nil {
+			return nil, mapError(err)
+		}
+		out = append(out, toTokens(reply))
+	}
+	if err := <-sent; err != nil && !errors.Is(err, io.EOF) {
 		return nil, err
 	}
-	return resp.GetWords(), nil
+	return out, nil
+}
+
+func toTokens(reply *wordspb.WordsReply) []core.Token {
+	words, positions := reply.GetWords(), reply.GetPositions()
+	tokens := make([]core.Token, 0, len(words))
+	for i, w := range words {
+		pos := i
+		if i < len(positions) {
+			pos = int(positions[i])
+		}
+		tokens = append(tokens, core.Token{Stem: w, Pos: pos})
+	}
+	return tokens
+}
+
+func mapError(err error) error {
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.ResourceExhausted:
+			return core.ErrRequestTooLarge
+		case codes.InvalidArgument:
+			return core.ErrBadArguments
+		}
+	}
+	return err
+}
+
+// Version returns the version of the normalization of the profile.
+func (c Client) Version(ctx context.Context) (string, error) {
+	resp, err := c.client.Version(ctx, &wordspb.VersionRequest{Profile: c.profile})
+	if err != nil {
+		return "", mapError(err)
+	}
+	return resp.GetVersion(), nil
 }
 
 func (c Client) Ping(ctx context.Context) error {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"yadro.com/course/update/core"
@@ -37,6 +38,19 @@ type comicResp struct {
 	Title      string `json:"safe_title"`
 	Transcript string `json:"transcript"`
 	Alt        string `json:"alt"`
+	Year       string `json:"year"`
+	Month      string `json:"month"`
+	Day        string `json:"day"`
+}
+
+func (cr comicResp) published() time.Time {
+	year, errY := strconv.Atoi(cr.Year)
+	month, errM := strconv.Atoi(cr.Month)
+	day, errD := strconv.Atoi(cr.Day)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 }
 
 func (c *Client) getJSON(ctx context.Context, url string, out any) error {
@@ -98,10 +112,13 @@ func (c *Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
 		return core.XKCDInfo{}, err
 	}
 	return core.XKCDInfo{
-		ID:          cr.Num,
-		URL:         cr.Img,
-		Title:       cr.Title,
-		Description: cr.Alt + " " + cr.Transcript,
+		ID:         cr.Num,
+		URL:        cr.Img,
+		Title:      cr.Title,
+		Alt:        cr.Alt,
+		Transcript: cr.Transcript,
+		Published:  cr.published(),
+		PageURL:    fmt.Sprintf("%s/%d/", c.baseURL, cr.Num),
 	}, nil
 }
 
@@ -12,6 +12,10 @@ type XKCD struct {
 	Concurrency int           `yaml:"concurrency" env:"XKCD_CONCURRENCY" env-default:"1"`
 	Timeout     time.Duration `yaml:"timeout" env:"XKCD_TIMEOUT" env-default:"10s"`
 	CheckPeriod time.Duration `yaml:"check_period" env:"XKCD_CHECK_PERIOD" env-default:"1h"`
+	Schedule    string        `yaml:"schedule" env:"XKCD_SCHEDULE"`
+	Jitter      time.Duration `yaml:"jitter" env:"XKCD_JITTER" env-default:"1m"`
+	RetryBase   time.Duration `yaml:"retry_base" env:"XKCD_RETRY_BASE" env-default:"5m"`
+	RetryMax    time.Duration `yaml:"retry_max" env:"XKCD_RETRY_MAX" env-default:"24h"`
 }
 
 type Config struct {
@@ -21,6 +25,7 @@ type Config struct {
 	DBAddress     string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	WordsAddress  string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
 	BrokerAddress string `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"nats://localhost:4222"`
+	WordsProfile  string `yaml:"words_profile" env:"WORDS_PROFILE" env-default:"default"`
 }
 
 func MustLoad(configPath string) Config {
@@ -28,5 +33,9 @@ func MustLoad(configPath string) Config {
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		log.Fatalf("cannot read config %q: %s", configPath, err)
 	}
+	if cfg.XKCD.RetryBase <= 0 || cfg.XKCD.RetryMax < cfg.XKCD.RetryBase {
+		log.Fatalf("bad retry backoff: retry_base %s must be positive and not above retry_max %s",
+			cfg.XKCD.RetryBase, cfg.XKCD.RetryMax)
+	}
 	return cfg
 }
@@ -6,3 +6,4 @@ var ErrBadArguments = errors.New("arguments are not acceptable")
 var ErrAlreadyExists = errors.New("resource or task already exists")
 var ErrNotFound = errors.New("resource is not found")
 var ErrRequestTooLarge = errors.New("request is too large")
+var ErrNotRunning = errors.New("no update is running")
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Backoff is the delay before a failed comic is retried: Base after the
+// first failure, doubled after every next one up to Max.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b Backoff) Delay(attempts int) time.Duration {
+	d := b.Base
+	for i := 1; i < attempts && d < b.Max; i++ {
+		d *= 2
+	}
+	return min(d, b.Max)
+}
+
+// Failures returns the comics that failed to be fetched or stored, the ones
+// due for a retry first.
+func (s *Service) Failures(ctx context.Context, limit int) ([]Failure, error) {
+	if limit < 0 {
+		return nil, ErrBadArguments
+	}
+	if limit == 0 {
+		limit = defaultFailures
+	}
+	return s.db.Failures(ctx, min(limit, maxFailures))
+}
+
+// dueFailures splits the failed comics into those due for a retry and those
+// still backing off.
+func dueFailures(failures []Failure, now time.Time) (due, held map[int]struct{}) {
+	due = make(map[int]struct{})
+	held = make(map[int]struct{})
+	for _, f := range failures {
+		if f.NextRetryAt.After(now) {
+			held[f.ID] = struct{}{}
+		} else {
+			due[f.ID] = struct{}{}
+		}
+	}
+	return due, held
+}
+
+// failureLog records the comics a job fails on, so that later jobs retry
+// them after a backoff, and clears those it stores at last. A comic that
+// has never been stored is also kept as failed in the comics.
+type failureLog struct {
+	log     *slog.Logger
+	db      DB
+	backoff Backoff
+	known   map[int]Failure
+
+	mu    sync.Mutex
+	fixed []int
+}
+
+func (s *Service) newFailureLog(failures []Failure) *failureLog {
+	known := make(map[int]Failure, len(failures))
+	for _, f := range failures {
+		known[f.ID] = f
+	}
+	return &failureLog{log: s.log, db: s.db, backoff: s.backoff, known: known}
+}
+
+func (l *failureLog) fail(ctx context.Context, id int, class FailureClass, err error) {
+	now := time.Now()
+	attempts := l.known[id].Attempts + 1
+	f := Failure{
+		ID:           id,
+		Class:        class,
+		Error:        err.Error(),
+		Attempts:     attempts,
+		LastFailedAt: now,
+		NextRetryAt:  now.Add(l.backoff.Delay(attempts)),
+	}
+	if err := l.db.SaveFailure(ctx, f); err != nil {
+		l.log.Warn("cannot record failure", "id", id, "error", err)
+	}
+	if err := l.db.Add(ctx, Comics{ID: id, Status: ComicFailed}); err != nil {
+		l.log.Warn("cannot mark comic failed", "id", id, "error", err)
+	}
+}
+
+func (l *failureLog) ok(id int) {
+	if _, ok := l.known[id]; !ok {
+		return
+	}
+	l.mu.Lock()
+	l.fixed = append(l.fixed, id)
+	l.mu.Unlock()
+}
+
+// close forgets the failures of the comics stored by the job.
+func (l *failureLog) close(ctx context.Context) {
+	if len(l.fixed) == 0 {
+		return
+	}
+	if err := l.db.ClearFailures(ctx, l.fixed); err != nil {
+		l.log.Warn("cannot clear failures", "comics", len(l.fixed), "error", err)
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type ServiceStatus string
 
 const (
@@ -7,10 +9,14 @@ const (
 	StatusIdle    ServiceStatus = "idle"
 )
 
+// DBStats counts the words of the comics fetched and the comics stored with
+// each status.
 type DBStats struct {
-	WordsTotal    int
-	WordsUnique   int
-	ComicsFetched int
+	WordsTotal     int
+	WordsUnique    int
+	ComicsFetched  int
+	ComicsNotFound int
+	ComicsFailed   int
 }
 
 type ServiceStats struct {
@@ -18,15 +24,123 @@ type ServiceStats struct {
 	ComicsTotal int
 }
 
-type Comics struct {
-	ID    int
-	URL   string
-	Words []string
+type Field int16
+
+const (
+	FieldUnknown Field = iota
+	FieldTitle
+	FieldAlt
+	FieldTranscript
+)
+
+type Token struct {
+	Stem string
+	Pos  int
 }
 
-type XKCDInfo struct {
+// ComicStatus tells a comic fetched from xkcd from one xkcd has no page for,
+// like 404, and one that could not be fetched or stored yet.
+type ComicStatus string
+
+const (
+	ComicOK       ComicStatus = "ok"
+	ComicNotFound ComicStatus = "not_found"
+	ComicFailed   ComicStatus = "failed"
+)
+
+// Comics keeps the stems of a comic with their positions and source fields,
+// all three slices are parallel, and the version of the words service
+// normalization they came from.
+type Comics struct {
 	ID          int
+	Status      ComicStatus
 	URL         string
-	Description string
 	Title       string
+	Alt         string
+	Transcript  string
+	Published   time.Time
+	PageURL     string
+	Words       []string
+	Positions   []int
+	Fields      []Field
+	NormVersion string
+}
+
+type XKCDInfo struct {
+	ID         int
+	URL        string
+	Title      string
+	Alt        string
+	Transcript string
+	Published  time.Time
+	PageURL    string
+}
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+type JobTrigger string
+
+const (
+	TriggerManual    JobTrigger = "manual"
+	TriggerScheduled JobTrigger = "scheduled"
+	TriggerRefetch   JobTrigger = "refetch"
+	TriggerReindex   JobTrigger = "reindex"
+)
+
+// Job is one update run kept in the job history. Done counts comics stored,
+// Failed those that could not be fetched or stored, and Placeholders the IDs
+// xkcd has no comic for.
+type Job struct {
+	ID           int64
+	Trigger      JobTrigger
+	State        JobState
+	Total        int
+	Done         int
+	Failed       int
+	Placeholders int
+	Errors       []string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+type IDRange struct {
+	From int
+	To   int
+}
+
+// Refetch selects comics to fetch and normalize again: the IDs and ranges
+// given plus, when asked, every placeholder or every comic stored without
+// words.
+type Refetch struct {
+	IDs          []int
+	Ranges       []IDRange
+	Placeholders bool
+	EmptyWords   bool
+}
+
+type FailureClass string
+
+const (
+	FailureFetch     FailureClass = "fetch"
+	FailureNormalize FailureClass = "normalize"
+	FailureStore     FailureClass = "store"
+)
+
+// Failure is a comic a job failed to fetch or store, retried by later jobs
+// once NextRetryAt has passed.
+type Failure struct {
+	ID            int
+	Class         FailureClass
+	Error         string
+	Attempts      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+	NextRetryAt   time.Time
 }
@@ -2,20 +2,52 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Updater interface {
-	Update(context.Context) error
+	Update(context.Context) (Job, error)
+	Cancel(context.Context) (Job, error)
+	Refetch(context.Context, Refetch) (Job, error)
+	Reindex(context.Context) (Job, error)
+	Failures(context.Context, int) ([]Failure, error)
+	Job(context.Context, int64) (Job, error)
+	Jobs(context.Context, int) ([]Job, error)
 	Stats(context.Context) (ServiceStats, error)
 	Status(context.Context) ServiceStatus
+	Progress(context.Context) Progress
+	WatchProgress(context.Context) <-chan Progress
+	NextRun(context.Context) time.Time
 	Drop(context.Context) error
 }
 
 type DB interface {
+	// Add stores the comic over the one stored before unless it is failed
+	// and the stored one is not.
 	Add(context.Context, Comics) error
 	Stats(context.Context) (DBStats, error)
 	Drop(context.Context) error
+	// IDs lists the comics stored ok or not found, failed ones excluded.
 	IDs(context.Context) ([]int, error)
+	PlaceholderIDs(context.Context) ([]int, error)
+	EmptyIDs(context.Context) ([]int, error)
+	// StaleComics lists the comics fetched and normalized with a version
+	// other than the one given, after the ID given and by ID.
+	StaleComics(ctx context.Context, version string, after, limit int) ([]Comics, error)
+	CountStale(ctx context.Context, version string) (int, error)
+	// UpdateWords replaces the stems of a fetched comic.
+	UpdateWords(context.Context, Comics) error
+	CreateJob(context.Context, Job) (int64, error)
+	FinishJob(context.Context, Job) error
+	Job(context.Context, int64) (Job, error)
+	Jobs(ctx context.Context, limit int) ([]Job, error)
+	// InterruptJobs fails the running jobs and returns them.
+	InterruptJobs(ctx context.Context, reason string) ([]Job, error)
+	SaveFailure(context.Context, Failure) error
+	ClearFailures(ctx context.Context, ids []int) error
+	// Failures lists failures by their next retry, all of them when limit
+	// is zero.
+	Failures(ctx context.Context, limit int) ([]Failure, error)
 }
 
 type XKCD interface {
@@ -24,5 +56,8 @@ type XKCD interface {
 }
 
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	NormBatch(ctx context.Context, phrases []string) ([][]Token, error)
+	// Version changes whenever the words service would give other stems
+	// for the same text.
+	Version(ctx context.Context) (string, error)
 }
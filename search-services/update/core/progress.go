@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxJobErrors bounds the errors kept per job; the counters still count
+// every failure.
+const maxJobErrors = 20
+
+// Progress describes the running or the last finished update job, whose ID
+// is zero before the first one.
+type Progress struct {
+	Status ServiceStatus
+	Job
+	ETA time.Time
+}
+
+// eta extrapolates the time the job finishes at from its rate so far.
+func (p Progress) eta(now time.Time) time.Time {
+	processed := p.Done + p.Failed + p.Placeholders
+	if p.Status != StatusRunning || processed == 0 || processed >= p.Total {
+		return time.Time{}
+	}
+	elapsed := now.Sub(p.StartedAt)
+	left := time.Duration(float64(elapsed) / float64(processed) * float64(p.Total-processed))
+	return now.Add(left)
+}
+
+// tracker keeps the progress of update jobs and pushes every change to its
+// watchers. A watcher that falls behind gets only the latest progress.
+type tracker struct {
+	mu       sync.Mutex
+	progress Progress
+	watchers map[chan Progress]struct{}
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		progress: Progress{Status: StatusIdle},
+		watchers: make(map[chan Progress]struct{}),
+	}
+}
+
+func (t *tracker) start(job Job) {
+	t.update(func(p *Progress) {
+		*p = Progress{Status: StatusRunning, Job: job}
+	})
+}
+
+// finish ends the running job in the state given, keeping err if any, and
+// returns the job.
+func (t *tracker) finish(state JobState, err error, now time.Time) Job {
+	var job Job
+	t.update(func(p *Progress) {
+		p.Status = StatusIdle
+		p.State = state
+		if err != nil {
+			p.Errors = append(p.Errors, err.Error())
+		}
+		p.FinishedAt = now
+		job = p.Job
+	})
+	return job
+}
+
+// fail counts a failure of the running job and keeps its error.
+func (t *tracker) fail(err error) {
+	t.update(func(p *Progress) {
+		p.Failed++
+		if len(p.Errors) < maxJobErrors {
+			p.Errors = append(p.Errors, err.Error())
+		}
+	})
+}
+
+func (t *tracker) update(fn func(*Progress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.progress)
+	p := t.snapshotLocked()
+	for ch := range t.watchers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- p
+	}
+}
+
+func (t *tracker) snapshot() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshotLocked()
+}
+
+func (t *tracker) snapshotLocked() Progress {
+	p := t.progress
+	p.ETA = p.eta(time.Now())
+	return p
+}
+
+// watch sends the current progress and then every change of it until ctx
+// is done, when the channel is closed.
+func (t *tracker) watch(ctx context.Context) <-chan Progress {
+	ch := make(chan Progress, 1)
+	t.mu.Lock()
+	t.watchers[ch] = struct{}{}
+	ch <- t.snapshotLocked()
+	t.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		delete(t.watchers, ch)
+		close(ch)
+		t.mu.Unlock()
+	})
+	return ch
+}
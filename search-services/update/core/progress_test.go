@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracker_Watch(t *testing.T) {
+	tr := newTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := tr.watch(ctx)
+
+	if p := <-ch; p.Status != StatusIdle || !p.StartedAt.IsZero() {
+		t.Fatalf("unexpected initial progress %+v", p)
+	}
+
+	start := time.Now().Add(-time.Minute)
+	tr.start(Job{ID: 7, State: JobRunning, StartedAt: start})
+	tr.update(func(p *Progress) { p.Total = 4 })
+	tr.update(func(p *Progress) { p.Done++ })
+	tr.update(func(p *Progress) { p.Placeholders++ })
+	tr.fail(errors.New("boom"))
+
+	// a slow watcher gets the latest progress only
+	p := <-ch
+	if p.Status != StatusRunning || p.ID != 7 || p.Total != 4 || p.Done != 1 || p.Placeholders != 1 || p.Failed != 1 {
+		t.Fatalf("unexpected progress %+v", p)
+	}
+	if p.ETA.Before(time.Now().Add(15*time.Second)) || p.ETA.After(time.Now().Add(25*time.Second)) {
+		t.Fatalf("unexpected ETA %v", p.ETA)
+	}
+
+	job := tr.finish(JobFailed, errors.New("canceled"), time.Now())
+	if job.State != JobFailed || len(job.Errors) != 2 || job.Errors[1] != "canceled" {
+		t.Fatalf("unexpected finished job %+v", job)
+	}
+	if p := <-ch; p.Status != StatusIdle || p.FinishedAt.IsZero() || !p.ETA.IsZero() {
+		t.Fatalf("unexpected final progress %+v", p)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel closed after cancel")
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// maxRefetchIDs bounds the comics named by the IDs and ranges of one
+// refetch.
+const maxRefetchIDs = 100_000
+
+// Refetch starts a job fetching the selected comics from xkcd again and
+// replacing the stored ones, and returns it without waiting for it to
+// finish. When a job is already running, it is returned with
+// ErrAlreadyExists.
+func (s *Service) Refetch(ctx context.Context, r Refetch) (Job, error) {
+	if err := r.validate(); err != nil {
+		return Job{}, err
+	}
+	jobCtx, job, err := s.begin(context.WithoutCancel(ctx), TriggerRefetch)
+	if err != nil {
+		return job, err
+	}
+	go s.run(jobCtx, job, s.fetching(func(ctx context.Context, _ []Failure) ([]int, error) {
+		return s.refetchIDs(ctx, r)
+	}))
+	return job, nil
+}
+
+// validate checks the selection and leaves its IDs sorted and unique.
+func (r *Refetch) validate() error {
+	if len(r.IDs) == 0 && len(r.Ranges) == 0 && !r.Placeholders && !r.EmptyWords {
+		return fmt.Errorf("%w: nothing to refetch", ErrBadArguments)
+	}
+	for _, id := range r.IDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: bad comic id %d", ErrBadArguments, id)
+		}
+	}
+	r.IDs = slices.Compact(slices.Sorted(slices.Values(r.IDs)))
+	if len(r.IDs) > maxRefetchIDs {
+		return fmt.Errorf("%w: more than %d comics", ErrBadArguments, maxRefetchIDs)
+	}
+	budget := maxRefetchIDs - len(r.IDs)
+	for _, rng := range r.Ranges {
+		if rng.From <= 0 || rng.From > rng.To {
+			return fmt.Errorf("%w: bad range %d-%d", ErrBadArguments, rng.From, rng.To)
+		}
+		// To-From cannot overflow as both are positive
+		if rng.To-rng.From >= budget {
+			return fmt.Errorf("%w: more than %d comics", ErrBadArguments, maxRefetchIDs)
+		}
+		budget -= rng.To - rng.From + 1
+	}
+	return nil
+}
+
+// refetchIDs resolves the selection to sorted unique IDs of comics xkcd has
+// published; later ones are left out so no placeholders are stored for
+// them.
+func (s *Service) refetchIDs(ctx context.Context, r Refetch) ([]int, error) {
+	last, err := s.xkcd.LastID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := slices.Clone(r.IDs)
+	for _, rng := range r.Ranges {
+		for id := rng.From; id <= min(rng.To, last); id++ {
+			ids = append(ids, id)
+		}
+	}
+	if r.Placeholders {
+		placeholders, err := s.db.PlaceholderIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, placeholders...)
+	}
+	if r.EmptyWords {
+		empty, err := s.db.EmptyIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, empty...)
+	}
+
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if i, _ := slices.BinarySearch(ids, last+1); i < len(ids) {
+		ids = ids[:i]
+	}
+	return ids, nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Reindex starts a job normalizing the stored comics again with the current
+// version of the words service, without fetching them from xkcd, and
+// returns it without waiting for it to finish. Comics normalized with the
+// current version are skipped, so a job cut short is resumed by the next
+// one. When a job is already running, it is returned with ErrAlreadyExists.
+func (s *Service) Reindex(ctx context.Context) (Job, error) {
+	jobCtx, job, err := s.begin(context.WithoutCancel(ctx), TriggerReindex)
+	if err != nil {
+		return job, err
+	}
+	go s.run(jobCtx, job, s.reindex)
+	return job, nil
+}
+
+// reindex normalizes the stale comics again batch after batch in the order
+// of their IDs. It returns no IDs, as the search index is rebuilt after it.
+func (s *Service) reindex(ctx context.Context) ([]int, error) {
+	version, err := s.words.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.db.CountStale(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	s.progress.update(func(p *Progress) { p.Total = total })
+	s.log.Info("reindexing comics", "comics", total, "version", version)
+
+	// comics already normalized are stored even when the job is cancelled
+	storeCtx := context.WithoutCancel(ctx)
+	after := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stale, err := s.db.StaleComics(ctx, version, after, normBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(stale) == 0 {
+			return nil, nil
+		}
+		after = stale[len(stale)-1].ID
+
+		batch := make([]XKCDInfo, 0, len(stale))
+		for _, c := range stale {
+			// normalizing no text would wipe the words of the comic
+			if c.Title == "" && c.Alt == "" && c.Transcript == "" {
+				s.log.Warn("comic without texts skipped", "id", c.ID)
+				continue
+			}
+			batch = append(batch, XKCDInfo{ID: c.ID, Title: c.Title, Alt: c.Alt, Transcript: c.Transcript})
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		comics, err := s.tokenize(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.log.Warn("words normalize failed", "comics", len(batch), "error", err)
+			s.progress.update(func(p *Progress) {
+				p.Failed += len(batch)
+				if len(p.Errors) < maxJobErrors {
+					p.Errors = append(p.Errors, fmt.Sprintf("normalize %d comics: %v", len(batch), err))
+				}
+			})
+			continue
+		}
+		for _, c := range comics {
+			if err := s.db.UpdateWords(storeCtx, c); err != nil {
+				s.log.Warn("db update words failed", "id", c.ID, "error", err)
+				s.progress.fail(fmt.Errorf("update words %d: %w", c.ID, err))
+				continue
+			}
+			s.progress.update(func(p *Progress) { p.Done++ })
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, time.January, 17, 10, 29, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 17, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, time.January, 18, 3, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2024, time.January, 17, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * 0,6", time.Date(2024, time.January, 20, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * 1", time.Date(2024, time.January, 22, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", from.Add(90 * time.Minute)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestParseSchedule_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *",
+		"5-1 * * * *", "a * * * *", "@every", "@every -1h", "@sometimes",
+	} {
+		if _, err := ParseSchedule(expr); !errors.Is(err, ErrBadArguments) {
+			t.Errorf("ParseSchedule(%q): expected ErrBadArguments, got %v", expr, err)
+		}
+	}
+}
@@ -3,19 +3,34 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"strings"
 	"sync"
 	"sync/atomic"
-	"unicode/utf8"
+	"time"
 )
 
 const (
-	maxWordsPhraseLen = 4096
-	placeholderURL    = "missing"
+	// fieldGap separates positions of consecutive fields so that phrase and
+	// proximity matches never span two fields.
+	fieldGap = 1000
+
+	// normBatchSize is the number of fetched comics normalized per call to
+	// the words service.
+	normBatchSize = 100
+
+	defaultJobs = 20
+	maxJobs     = 100
+
+	defaultFailures = 100
+	maxFailures     = 1000
 )
 
 type Events interface {
-	PublishDBUpdated()
+	PublishDBUpdated(ids []int)
+	PublishDBReconcile()
 }
 
 type Service struct {
@@ -25,13 +40,16 @@ type Service struct {
 	words       Words
 	events      Events
 	concurrency int
+	backoff     Backoff
 
-	mu     sync.Mutex
-	status atomic.Value
+	mu       sync.Mutex
+	running  atomic.Pointer[runningJob]
+	progress *tracker
+	nextRun  atomic.Value
 }
 
 func NewService(
-	log *slog.Logger, db DB, xkcd XKCD, words Words, events Events, concurrency int,
+	log *slog.Logger, db DB, xkcd XKCD, words Words, events Events, concurrency int, backoff Backoff,
 ) (*Service, error) {
 	if concurrency < 1 {
 		return nil, errors.New("wrong concurrency specified")
@@ -43,38 +61,182 @@ func NewService(
 		words:       words,
 		events:      events,
 		concurrency: concurrency,
+		backoff:     backoff,
+		progress:    newTracker(),
 	}
-	s.status.Store(StatusIdle)
 	return s, nil
 }
 
-func (s *Service) Update(ctx context.Context) (err error) {
+// errCancelled is the cause of a job context cancelled by Cancel.
+var errCancelled = errors.New("update cancelled")
+
+// runningJob lets Cancel stop the running job and wait for its end.
+type runningJob struct {
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	job    Job
+}
+
+// Update starts a manual update job and returns it without waiting for it
+// to finish. When a job is already running, it is returned with
+// ErrAlreadyExists.
+func (s *Service) Update(ctx context.Context) (Job, error) {
+	jobCtx, job, err := s.begin(context.WithoutCancel(ctx), TriggerManual)
+	if err != nil {
+		return job, err
+	}
+	go s.run(jobCtx, job, s.fetching(s.missingIDs))
+	return job, nil
+}
+
+// Cancel stops the running job and returns it once its workers are done.
+// Comics stored before are kept and announced as usual.
+func (s *Service) Cancel(ctx context.Context) (Job, error) {
+	r := s.running.Load()
+	if r == nil {
+		return Job{}, ErrNotRunning
+	}
+	r.cancel(errCancelled)
+	select {
+	case <-r.done:
+		return r.job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+// begin takes the update lock and records a new job, which run must end
+// with the context returned.
+func (s *Service) begin(ctx context.Context, trigger JobTrigger) (context.Context, Job, error) {
 	if !s.mu.TryLock() {
-		return ErrAlreadyExists
+		return nil, s.progress.snapshot().Job, ErrAlreadyExists
 	}
-	s.status.Store(StatusRunning)
-	defer func() {
-		s.status.Store(StatusIdle)
+	job := Job{Trigger: trigger, State: JobRunning, StartedAt: time.Now()}
+	id, err := s.db.CreateJob(ctx, job)
+	if err != nil {
 		s.mu.Unlock()
-		if err == nil && s.events != nil {
-			s.log.Info("publishing db updated event")
-			s.events.PublishDBUpdated()
+		return nil, Job{}, fmt.Errorf("create job: %w", err)
+	}
+	job.ID = id
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	s.running.Store(&runningJob{cancel: cancel, done: make(chan struct{})})
+	s.progress.start(job)
+	s.log.Info("update job started", "job", job.ID, "trigger", job.Trigger)
+	return jobCtx, job, nil
+}
+
+// run does the work of the job begun, which returns the IDs of the comics
+// stored, saves the job to the history and releases the update lock.
+func (s *Service) run(ctx context.Context, job Job, work func(context.Context) ([]int, error)) {
+	added, err := work(ctx)
+	state := JobSucceeded
+	switch {
+	case errors.Is(context.Cause(ctx), errCancelled):
+		state, err = JobCancelled, nil
+	case err != nil:
+		state = JobFailed
+	}
+	job = s.progress.finish(state, err, time.Now())
+	if err := s.db.FinishJob(context.WithoutCancel(ctx), job); err != nil {
+		s.log.Error("cannot save job", "job", job.ID, "error", err)
+	}
+
+	r := s.running.Swap(nil)
+	r.job = job
+	r.cancel(nil)
+	s.mu.Unlock()
+	close(r.done)
+
+	s.log.Info("update job finished", "job", job.ID, "state", job.State,
+		"done", job.Done, "failed", job.Failed, "placeholders", job.Placeholders)
+	switch {
+	case s.events == nil:
+	case job.Trigger == TriggerReindex && job.Done > 0:
+		s.log.Info("publishing db reconcile event", "reindexed", job.Done)
+		s.events.PublishDBReconcile()
+	case len(added) > 0:
+		s.log.Info("publishing db updated event", "added", len(added))
+		s.events.PublishDBUpdated(added)
+	}
+}
+
+// fetching makes the work of a job fetching the comics planned. The failures
+// recorded before are loaded once for the planning and the job.
+func (s *Service) fetching(
+	plan func(context.Context, []Failure) ([]int, error),
+) func(context.Context) ([]int, error) {
+	return func(ctx context.Context) ([]int, error) {
+		failures, err := s.db.Failures(ctx, 0)
+		if err != nil {
+			return nil, err
 		}
-	}()
+		ids, err := plan(ctx, failures)
+		if err != nil {
+			return nil, err
+		}
+		return s.fetch(ctx, ids, s.newFailureLog(failures))
+	}
+}
 
+// missingIDs lists the comics published on xkcd but not stored yet and
+// the ones failed before that are due for a retry.
+func (s *Service) missingIDs(ctx context.Context, failures []Failure) ([]int, error) {
 	last, err := s.xkcd.LastID(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	existing, err := s.db.IDs(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	due, held := dueFailures(failures, time.Now())
 	exists := make(map[int]struct{}, len(existing))
 	for _, id := range existing {
 		exists[id] = struct{}{}
 	}
+	var missing []int
+	for id := 1; id <= last; id++ {
+		_, stored := exists[id]
+		_, retry := due[id]
+		_, wait := held[id]
+		if retry || !stored && !wait {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// fetch gets the comics from xkcd with a pool of workers, normalizes them
+// in batches and stores them, replacing any stored before. It returns the
+// IDs stored, the ones xkcd has no comic for included, so that search drops
+// those it had.
+func (s *Service) fetch(ctx context.Context, ids []int, failures *failureLog) ([]int, error) {
+	s.progress.update(func(p *Progress) { p.Total = len(ids) })
 
+	// comics already fetched are stored even when the job is cancelled
+	storeCtx := context.WithoutCancel(ctx)
+	defer failures.close(storeCtx)
+	var added []int
+	fetched := make(chan XKCDInfo, normBatchSize)
+	stored := make(chan struct{})
+	go func() {
+		defer close(stored)
+		batch := make([]XKCDInfo, 0, normBatchSize)
+		for info := range fetched {
+			batch = append(batch, info)
+			if len(batch) == normBatchSize {
+				added = append(added, s.store(storeCtx, batch, failures)...)
+				batch = batch[:0]
+			}
+		}
+		added = append(added, s.store(storeCtx, batch, failures)...)
+	}()
+
+	var (
+		mu       sync.Mutex
+		notFound []int
+	)
 	jobs := make(chan int, s.concurrency*2)
 	var wg sync.WaitGroup
 	worker := func() {
@@ -87,49 +249,52 @@ func (s *Service) Update(ctx context.Context) (err error) {
 
 			info, err := s.xkcd.Get(ctx, id)
 			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
 				if errors.Is(err, ErrNotFound) {
-					if dbErr := s.db.Add(ctx, Comics{ID: id, URL: placeholderURL, Words: []string{}}); dbErr != nil {
+					if dbErr := s.db.Add(storeCtx, Comics{ID: id, Status: ComicNotFound}); dbErr != nil {
 						s.log.Warn("db add placeholder failed", "id", id, "error", dbErr)
+						s.progress.fail(fmt.Errorf("add placeholder %d: %w", id, dbErr))
+						failures.fail(storeCtx, id, FailureStore, dbErr)
+						continue
 					}
+					s.progress.update(func(p *Progress) { p.Placeholders++ })
+					failures.ok(id)
+					mu.Lock()
+					notFound = append(notFound, id)
+					mu.Unlock()
 					continue
 				}
 				s.log.Warn("xkcd get failed", "id", id, "error", err)
+				s.progress.fail(fmt.Errorf("fetch comic %d: %w", id, err))
+				failures.fail(storeCtx, id, FailureFetch, err)
 				continue
 			}
 
-			phrase := info.Title + " " + info.Description
-			phrase = truncateUTF8ToBytes(phrase, maxWordsPhraseLen)
-
-			ws, err := s.words.Norm(ctx, phrase)
-			if err != nil {
-				s.log.Warn("words normalize failed", "id", id, "error", err)
-				ws = []string{}
-			}
-			if err := s.db.Add(ctx, Comics{ID: info.ID, URL: info.URL, Words: ws}); err != nil {
-				s.log.Warn("db add failed", "id", id, "error", err)
-				continue
-			}
+			fetched <- info
 		}
 	}
 
 	for i := 0; i < s.concurrency; i++ {
 		wg.Go(worker)
 	}
-	for id := 1; id <= last; id++ {
-		if _, ok := exists[id]; ok {
-			continue
-		}
+	for _, id := range ids {
 		select {
 		case <-ctx.Done():
 			close(jobs)
 			wg.Wait()
-			return ctx.Err()
+			close(fetched)
+			<-stored
+			return append(added, notFound...), ctx.Err()
 		case jobs <- id:
 		}
 	}
 	close(jobs)
 	wg.Wait()
-	return nil
+	close(fetched)
+	<-stored
+	return append(added, notFound...), nil
 }
 
 func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
@@ -148,30 +313,272 @@ func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 }
 
 func (s *Service) Status(context.Context) ServiceStatus {
-	v := s.status.Load()
-	if v == nil {
-		return StatusIdle
+	return s.progress.snapshot().Status
+}
+
+// Progress returns the progress of the running update or, when idle, of the
+// last one.
+func (s *Service) Progress(context.Context) Progress {
+	return s.progress.snapshot()
+}
+
+// Job returns a job from the history, the running one with its current
+// counters.
+func (s *Service) Job(ctx context.Context, id int64) (Job, error) {
+	if id <= 0 {
+		return Job{}, ErrBadArguments
+	}
+	if p := s.progress.snapshot(); p.ID == id && p.State == JobRunning {
+		return p.Job, nil
+	}
+	return s.db.Job(ctx, id)
+}
+
+// Jobs returns the latest jobs from the history, newest first.
+func (s *Service) Jobs(ctx context.Context, limit int) ([]Job, error) {
+	if limit < 0 {
+		return nil, ErrBadArguments
+	}
+	if limit == 0 {
+		limit = defaultJobs
+	}
+	jobs, err := s.db.Jobs(ctx, min(limit, maxJobs))
+	if err != nil {
+		return nil, err
+	}
+	if p := s.progress.snapshot(); p.State == JobRunning {
+		for i := range jobs {
+			if jobs[i].ID == p.ID {
+				jobs[i] = p.Job
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// InterruptJobs fails the jobs left running by a previous process and
+// starts a reindex again when one of them was a reindex.
+func (s *Service) InterruptJobs(ctx context.Context) error {
+	jobs, err := s.db.InterruptJobs(ctx, "interrupted by restart")
+	if err != nil {
+		return err
 	}
-	return v.(ServiceStatus)
+	if len(jobs) == 0 {
+		return nil
+	}
+	s.log.Warn("interrupted update jobs failed", "jobs", len(jobs))
+	for _, job := range jobs {
+		if job.Trigger != TriggerReindex {
+			continue
+		}
+		resumed, err := s.Reindex(ctx)
+		if err != nil {
+			return fmt.Errorf("resume reindex: %w", err)
+		}
+		s.log.Info("interrupted reindex resumed", "interrupted", job.ID, "job", resumed.ID)
+		break
+	}
+	return nil
+}
+
+// WatchProgress streams the progress of updates, starting with the current
+// one, until ctx is done.
+func (s *Service) WatchProgress(ctx context.Context) <-chan Progress {
+	return s.progress.watch(ctx)
+}
+
+// NextRun returns the time of the next scheduled update check, zero when no
+// scheduler is running.
+func (s *Service) NextRun(context.Context) time.Time {
+	t, _ := s.nextRun.Load().(time.Time)
+	return t
+}
+
+// RunScheduler checks xkcd for new comics on the schedule until ctx is done
+// and updates the database when any have appeared. Every check is delayed by
+// a random part of jitter so that restarted replicas do not poll at once.
+func (s *Service) RunScheduler(ctx context.Context, schedule Schedule, jitter time.Duration) {
+	defer s.nextRun.Store(time.Time{})
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			s.log.Warn("update schedule never fires again")
+			return
+		}
+		if jitter > 0 {
+			next = next.Add(rand.N(jitter))
+		}
+		s.nextRun.Store(next)
+		s.log.Debug("next update check scheduled", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := s.updateIfNew(ctx); err != nil {
+			s.log.Error("scheduled update failed", "error", err)
+		}
+	}
+}
+
+// updateIfNew runs an update when xkcd has comics newer than the last one
+// stored; an update already in progress is left alone.
+func (s *Service) updateIfNew(ctx context.Context) error {
+	last, err := s.xkcd.LastID(ctx)
+	if err != nil {
+		return err
+	}
+	ids, err := s.db.IDs(ctx)
+	if err != nil {
+		return err
+	}
+	stored := 0
+	for _, id := range ids {
+		stored = max(stored, id)
+	}
+	failures, err := s.db.Failures(ctx, 0)
+	if err != nil {
+		return err
+	}
+	due, _ := dueFailures(failures, time.Now())
+	if last <= stored && len(due) == 0 {
+		s.log.Debug("no new comics", "last", last)
+		return nil
+	}
+
+	s.log.Info("new comics found, updating", "last", last, "stored", stored, "retries", len(due))
+	jobCtx, job, err := s.begin(ctx, TriggerScheduled)
+	if err != nil {
+		if errors.Is(err, ErrAlreadyExists) {
+			return nil
+		}
+		return err
+	}
+	s.run(jobCtx, job, s.fetching(s.missingIDs))
+	return nil
 }
 
 func (s *Service) Drop(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.db.Drop(ctx)
+	if err := s.db.Drop(ctx); err != nil {
+		return err
+	}
+	if s.events != nil {
+		s.log.Info("publishing db reconcile event")
+		s.events.PublishDBReconcile()
+	}
+	return nil
 }
 
-func truncateUTF8ToBytes(s string, limit int) string {
-	if len(s) <= limit {
-		return s
+// store normalizes a batch of fetched comics and adds them to the database,
+// returning the IDs added. A batch the words service fails on is skipped, so
+// its comics are fetched again by the next update.
+func (s *Service) store(ctx context.Context, batch []XKCDInfo, failures *failureLog) []int {
+	if len(batch) == 0 {
+		return nil
+	}
+	comics, err := s.tokenize(ctx, batch)
+	if err != nil {
+		s.log.Warn("words normalize failed", "comics", len(batch), "error", err)
+		s.progress.update(func(p *Progress) {
+			p.Failed += len(batch)
+			if len(p.Errors) < maxJobErrors {
+				p.Errors = append(p.Errors, fmt.Sprintf("normalize %d comics: %v", len(batch), err))
+			}
+		})
+		for _, info := range batch {
+			failures.fail(ctx, info.ID, FailureNormalize, err)
+		}
+		return nil
 	}
-	i := 0
-	for i < len(s) {
-		_, size := utf8.DecodeRuneInString(s[i:])
-		if i+size > limit {
-			break
+	ids := make([]int, 0, len(comics))
+	for _, c := range comics {
+		if err := s.db.Add(ctx, c); err != nil {
+			s.log.Warn("db add failed", "id", c.ID, "error", err)
+			s.progress.fail(fmt.Errorf("add comic %d: %w", c.ID, err))
+			failures.fail(ctx, c.ID, FailureStore, err)
+			continue
+		}
+		s.progress.update(func(p *Progress) { p.Done++ })
+		failures.ok(c.ID)
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// tokenize normalizes every field of the comics in one batch and lays out
+// the stems of each comic field after field, fieldGap positions apart.
+func (s *Service) tokenize(ctx context.Context, infos []XKCDInfo) ([]Comics, error) {
+	type source struct {
+		comic int
+		field Field
+	}
+	comics := make([]Comics, len(infos))
+	var phrases []string
+	var sources []source
+	for i, info := range infos {
+		comics[i] = Comics{
+			ID:         info.ID,
+			Status:     ComicOK,
+			URL:        info.URL,
+			Title:      info.Title,
+			Alt:        info.Alt,
+			Transcript: info.Transcript,
+			Published:  info.Published,
+			PageURL:    info.PageURL,
+		}
+		fields := []struct {
+			field Field
+			text  string
+		}{
+			{field: FieldTitle, text: info.Title},
+			{field: FieldAlt, text: info.Alt},
+			{field: FieldTranscript, text: info.Transcript},
+		}
+		for _, f := range fields {
+			if strings.TrimSpace(f.text) == "" {
+				continue
+			}
+			phrases = append(phrases, f.text)
+			sources = append(sources, source{comic: i, field: f.field})
+		}
+	}
+	version, err := s.words.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comics {
+		comics[i].NormVersion = version
+	}
+	if len(phrases) == 0 {
+		return comics, nil
+	}
+
+	tokens, err := s.words.NormBatch(ctx, phrases)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) != len(phrases) {
+		return nil, fmt.Errorf("words service normalized %d of %d phrases", len(tokens), len(phrases))
+	}
+
+	bases := make([]int, len(comics))
+	for i, src := range sources {
+		c := &comics[src.comic]
+		base := bases[src.comic]
+		last := base
+		for _, t := range tokens[i] {
+			c.Words = append(c.Words, t.Stem)
+			c.Positions = append(c.Positions, base+t.Pos)
+			c.Fields = append(c.Fields, src.field)
+			last = max(last, base+t.Pos)
 		}
-		i += size
+		bases[src.comic] = last + fieldGap
 	}
-	return s[:i]
+	return comics, nil
 }
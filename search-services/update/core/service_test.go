@@ -0,0 +1,498 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testDB struct {
+	mu       sync.Mutex
+	comics   map[int]Comics
+	jobs     map[int64]Job
+	failures map[int]Failure
+}
+
+func newTestDB() *testDB {
+	return &testDB{comics: map[int]Comics{}, jobs: map[int64]Job{}, failures: map[int]Failure{}}
+}
+
+func (db *testDB) Add(ctx context.Context, c Comics) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if old, ok := db.comics[c.ID]; ok && c.Status == ComicFailed && old.Status != ComicFailed {
+		return nil
+	}
+	db.comics[c.ID] = c
+	return nil
+}
+
+func (db *testDB) seed(comics ...Comics) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, c := range comics {
+		db.comics[c.ID] = c
+	}
+}
+
+func (db *testDB) comic(id int) Comics {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.comics[id]
+}
+
+func (db *testDB) allComics() map[int]Comics {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	comics := make(map[int]Comics, len(db.comics))
+	for id, c := range db.comics {
+		comics[id] = c
+	}
+	return comics
+}
+
+func (db *testDB) failure(id int) (Failure, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	f, ok := db.failures[id]
+	return f, ok
+}
+
+func (db *testDB) Stats(context.Context) (DBStats, error) { return DBStats{}, nil }
+func (db *testDB) Drop(context.Context) error             { return nil }
+
+func (db *testDB) IDs(context.Context) ([]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	ids := make([]int, 0, len(db.comics))
+	for id, c := range db.comics {
+		if c.Status != ComicFailed {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (db *testDB) PlaceholderIDs(context.Context) ([]int, error) { return db.where(ComicNotFound) }
+func (db *testDB) EmptyIDs(context.Context) ([]int, error)       { return nil, nil }
+
+func (db *testDB) where(status ComicStatus) ([]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var ids []int
+	for id, c := range db.comics {
+		if c.Status == status {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (db *testDB) StaleComics(_ context.Context, version string, after, limit int) ([]Comics, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var stale []Comics
+	for id, c := range db.comics {
+		hasText := c.Title != "" || c.Alt != "" || c.Transcript != ""
+		if c.Status == ComicOK && c.NormVersion != version && id > after && hasText {
+			stale = append(stale, c)
+		}
+	}
+	slices.SortFunc(stale, func(a, b Comics) int { return a.ID - b.ID })
+	return stale[:min(limit, len(stale))], nil
+}
+
+func (db *testDB) CountStale(ctx context.Context, version string) (int, error) {
+	stale, err := db.StaleComics(ctx, version, 0, math.MaxInt)
+	return len(stale), err
+}
+
+func (db *testDB) UpdateWords(_ context.Context, c Comics) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored := db.comics[c.ID]
+	stored.Words, stored.Positions, stored.Fields = c.Words, c.Positions, c.Fields
+	stored.NormVersion = c.NormVersion
+	db.comics[c.ID] = stored
+	return nil
+}
+
+func (db *testDB) CreateJob(_ context.Context, job Job) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	job.ID = int64(len(db.jobs) + 1)
+	db.jobs[job.ID] = job
+	return job.ID, nil
+}
+
+func (db *testDB) FinishJob(_ context.Context, job Job) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.jobs[job.ID] = job
+	return nil
+}
+
+func (db *testDB) Job(_ context.Context, id int64) (Job, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	job, ok := db.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+func (db *testDB) Jobs(context.Context, int) ([]Job, error) { return nil, nil }
+
+func (db *testDB) InterruptJobs(_ context.Context, reason string) ([]Job, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var jobs []Job
+	for id, job := range db.jobs {
+		if job.State == JobRunning {
+			job.State, job.Errors = JobFailed, append(job.Errors, reason)
+			db.jobs[id] = job
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (db *testDB) SaveFailure(_ context.Context, f Failure) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.failures[f.ID] = f
+	return nil
+}
+
+func (db *testDB) ClearFailures(_ context.Context, ids []int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, id := range ids {
+		delete(db.failures, id)
+	}
+	return nil
+}
+
+func (db *testDB) Failures(context.Context, int) ([]Failure, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	failures := make([]Failure, 0, len(db.failures))
+	for _, f := range db.failures {
+		failures = append(failures, f)
+	}
+	return failures, nil
+}
+
+// testXKCD serves comics up to fast at once and blocks on the rest until
+// the request is cancelled; comic broken fails while it is set and comic
+// gone is not found.
+type testXKCD struct {
+	last, fast int
+	gone       int
+	broken     *atomic.Int64
+}
+
+func (x testXKCD) LastID(context.Context) (int, error) { return x.last, nil }
+
+func (x testXKCD) Get(ctx context.Context, id int) (XKCDInfo, error) {
+	if x.broken != nil && int64(id) == x.broken.Load() {
+		return XKCDInfo{}, errors.New("server error")
+	}
+	if id == x.gone {
+		return XKCDInfo{}, ErrNotFound
+	}
+	if id > x.fast {
+		<-ctx.Done()
+		return XKCDInfo{}, ctx.Err()
+	}
+	return XKCDInfo{ID: id, Title: "comic"}, nil
+}
+
+type testWords struct {
+	version string
+}
+
+func (w testWords) Version(context.Context) (string, error) { return w.version, nil }
+
+func (testWords) NormBatch(_ context.Context, phrases []string) ([][]Token, error) {
+	tokens := make([][]Token, len(phrases))
+	for i, p := range phrases {
+		tokens[i] = []Token{{Stem: p}}
+	}
+	return tokens, nil
+}
+
+type testEvents struct {
+	updated    chan []int
+	reconciled chan struct{}
+}
+
+func (e testEvents) PublishDBUpdated(ids []int) { e.updated <- ids }
+func (e testEvents) PublishDBReconcile()        { e.reconciled <- struct{}{} }
+
+func TestService_Cancel(t *testing.T) {
+	db := newTestDB()
+	events := testEvents{updated: make(chan []int, 1)}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := NewService(log, db, testXKCD{last: 50, fast: 5}, testWords{}, events, 2, Backoff{})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	if _, err := s.Cancel(context.Background()); err != ErrNotRunning {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	started, err := s.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := s.Update(context.Background()); err != ErrAlreadyExists {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	// let the workers take the fast comics and block on the next ones
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Progress(context.Background()).Total == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	job, err := s.Cancel(context.Background())
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if job.ID != started.ID || job.State != JobCancelled || job.Done != 5 || job.Failed != 0 {
+		t.Fatalf("unexpected cancelled job %+v", job)
+	}
+	if saved, _ := db.Job(context.Background(), job.ID); saved.State != JobCancelled {
+		t.Fatalf("unexpected saved job %+v", saved)
+	}
+	if s.Status(context.Background()) != StatusIdle {
+		t.Fatal("expected idle service after cancel")
+	}
+
+	select {
+	case ids := <-events.updated:
+		slices.Sort(ids)
+		if !slices.Equal(ids, []int{1, 2, 3, 4, 5}) {
+			t.Fatalf("unexpected updated ids %v", ids)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected db updated event")
+	}
+}
+
+func TestService_Refetch(t *testing.T) {
+	db := newTestDB()
+	db.seed(
+		Comics{ID: 1, Status: ComicOK, URL: "one"},
+		Comics{ID: 3, Status: ComicNotFound},
+		Comics{ID: 4, Status: ComicOK, URL: "four"},
+	)
+	events := testEvents{updated: make(chan []int, 1)}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := NewService(log, db, testXKCD{last: 5, fast: 5, gone: 4}, testWords{}, events, 2, Backoff{})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	tooMany := make([]int, maxRefetchIDs+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	for _, r := range []Refetch{
+		{},
+		{IDs: []int{0}},
+		{Ranges: []IDRange{{From: 5, To: 4}}},
+		{IDs: tooMany},
+		{Ranges: []IDRange{{From: 1, To: math.MaxInt}}},
+		{IDs: []int{1, 2}, Ranges: []IDRange{{From: 1, To: maxRefetchIDs - 1}}},
+	} {
+		if _, err := s.Refetch(context.Background(), r); !errors.Is(err, ErrBadArguments) {
+			t.Fatalf("Refetch(%+v): expected ErrBadArguments, got %v", r.Ranges, err)
+		}
+	}
+	same := Refetch{IDs: slices.Repeat([]int{2, 1}, maxRefetchIDs)}
+	if err := same.validate(); err != nil || !slices.Equal(same.IDs, []int{1, 2}) {
+		t.Fatalf("expected IDs deduplicated, got %v, %v", same.IDs[:min(len(same.IDs), 3)], err)
+	}
+
+	job, err := s.Refetch(context.Background(), Refetch{
+		Placeholders: true,
+		Ranges:       []IDRange{{From: 4, To: 9}},
+	})
+	if err != nil {
+		t.Fatalf("Refetch: %v", err)
+	}
+	if job.Trigger != TriggerRefetch {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	select {
+	case ids := <-events.updated:
+		slices.Sort(ids)
+		if !slices.Equal(ids, []int{3, 4, 5}) {
+			t.Fatalf("unexpected refetched ids %v", ids)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected db updated event")
+	}
+	if job, _ = s.Job(context.Background(), job.ID); job.State != JobSucceeded ||
+		job.Total != 3 || job.Done != 2 || job.Placeholders != 1 {
+		t.Fatalf("unexpected finished job %+v", job)
+	}
+	if c := db.comic(3); c.Status != ComicOK || c.Title != "comic" {
+		t.Fatalf("placeholder not replaced: %+v", c)
+	}
+	if c := db.comic(4); c.Status != ComicNotFound {
+		t.Fatalf("comic gone from xkcd not marked: %+v", c)
+	}
+	if c := db.comic(1); c.URL != "one" {
+		t.Fatalf("unselected comic replaced: %+v", c)
+	}
+}
+
+func TestService_RetryFailures(t *testing.T) {
+	db := newTestDB()
+	events := testEvents{updated: make(chan []int, 1)}
+	var broken atomic.Int64
+	broken.Store(2)
+	xkcd := testXKCD{last: 3, fast: 3, broken: &broken}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := NewService(log, db, xkcd, testWords{}, events, 1, Backoff{Base: time.Hour, Max: time.Hour})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	update := func() []int {
+		t.Helper()
+		if _, err := s.Update(context.Background()); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		select {
+		case ids := <-events.updated:
+			slices.Sort(ids)
+			return ids
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected db updated event")
+			return nil
+		}
+	}
+
+	if ids := update(); !slices.Equal(ids, []int{1, 3}) {
+		t.Fatalf("unexpected stored ids %v", ids)
+	}
+	f, ok := db.failure(2)
+	if !ok || f.Class != FailureFetch || f.Attempts != 1 || time.Until(f.NextRetryAt) < 59*time.Minute {
+		t.Fatalf("unexpected failure %+v", f)
+	}
+	if c := db.comic(2); c.Status != ComicFailed {
+		t.Fatalf("expected failed comic, got %+v", c)
+	}
+
+	// the failure is held back until its retry is due
+	failures, err := db.Failures(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Failures failed: %v", err)
+	}
+	ids, err := s.missingIDs(context.Background(), failures)
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("expected nothing to update, got %v, %v", ids, err)
+	}
+
+	broken.Store(0)
+	f.NextRetryAt = time.Now()
+	if err := db.SaveFailure(context.Background(), f); err != nil {
+		t.Fatalf("SaveFailure failed: %v", err)
+	}
+	if ids := update(); !slices.Equal(ids, []int{2}) {
+		t.Fatalf("unexpected retried ids %v", ids)
+	}
+	for s.Status(context.Background()) != StatusIdle {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if failures, _ := db.Failures(context.Background(), 0); len(failures) != 0 {
+		t.Fatalf("expected failures cleared, got %+v", failures)
+	}
+	if c := db.comic(2); c.Status != ComicOK {
+		t.Fatalf("expected fetched comic, got %+v", c)
+	}
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: time.Minute, Max: 10 * time.Minute}
+	for attempts, want := range map[int]time.Duration{
+		1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 5: 10 * time.Minute, 100: 10 * time.Minute,
+	} {
+		if got := b.Delay(attempts); got != want {
+			t.Errorf("Delay(%d): expected %v, got %v", attempts, want, got)
+		}
+	}
+}
+
+func TestService_ResumeReindex(t *testing.T) {
+	db := newTestDB()
+	for id := 1; id <= 150; id++ {
+		db.seed(Comics{ID: id, Status: ComicOK, Title: "old", Words: []string{"old"}, NormVersion: "v1"})
+	}
+	db.seed(Comics{ID: 7, Status: ComicOK, Title: "new", Words: []string{"new"}, NormVersion: "v2"})
+	db.seed(Comics{ID: 404, Status: ComicNotFound})
+	// stored before texts were kept
+	db.seed(Comics{ID: 500, Status: ComicOK, Words: []string{"legacy"}})
+	if _, err := db.CreateJob(context.Background(), Job{Trigger: TriggerReindex, State: JobRunning}); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	events := testEvents{reconciled: make(chan struct{}, 1)}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := NewService(log, db, testXKCD{}, testWords{version: "v2"}, events, 1, Backoff{})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	if err := s.InterruptJobs(context.Background()); err != nil {
+		t.Fatalf("InterruptJobs: %v", err)
+	}
+	if job, _ := db.Job(context.Background(), 1); job.State != JobFailed {
+		t.Fatalf("expected interrupted job failed, got %+v", job)
+	}
+	select {
+	case <-events.reconciled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected db reconcile event")
+	}
+	for s.Status(context.Background()) != StatusIdle {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	job, err := s.Job(context.Background(), 2)
+	if err != nil || job.Trigger != TriggerReindex || job.State != JobSucceeded || job.Total != 149 || job.Done != 149 {
+		t.Fatalf("unexpected reindex job %+v, %v", job, err)
+	}
+	for id, c := range db.allComics() {
+		switch {
+		case id == 404:
+			if c.NormVersion != "" || c.Words != nil {
+				t.Fatalf("not found comic reindexed: %+v", c)
+			}
+		case id == 500:
+			if c.NormVersion != "" || !slices.Equal(c.Words, []string{"legacy"}) {
+				t.Fatalf("comic without texts reindexed: %+v", c)
+			}
+		case c.NormVersion != "v2" || !slices.Equal(c.Words, []string{c.Title}):
+			t.Fatalf("comic not reindexed: %+v", c)
+		}
+	}
+}
@@ -58,7 +58,7 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 
 	// words adapter
-	words, err := words.NewClient(cfg.WordsAddress, log)
+	words, err := words.NewClient(cfg.WordsAddress, cfg.WordsProfile, log)
 	if err != nil {
 		return fmt.Errorf("failed create Words client: %v", err)
 	}
@@ -71,10 +71,20 @@ func run(cfg config.Config, log *slog.Logger) error {
 	defer events.Close()
 
 	// service
-	updater, err := core.NewService(log, storage, xkcd, words, events, cfg.XKCD.Concurrency)
+	backoff := core.Backoff{Base: cfg.XKCD.RetryBase, Max: cfg.XKCD.RetryMax}
+	updater, err := core.NewService(log, storage, xkcd, words, events, cfg.XKCD.Concurrency, backoff)
 	if err != nil {
 		return fmt.Errorf("failed create Update service: %v", err)
 	}
+	if err := updater.InterruptJobs(context.Background()); err != nil {
+		return fmt.Errorf("failed to close interrupted jobs: %v", err)
+	}
+
+	// scheduler
+	schedule, err := makeSchedule(cfg.XKCD)
+	if err != nil {
+		return fmt.Errorf("failed to parse update schedule: %v", err)
+	}
 
 	// update server
 	listener, err := net.Listen("tcp", cfg.Address)
@@ -90,6 +100,10 @@ func run(cfg config.Config, log *slog.Logger) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	if schedule != nil {
+		go updater.RunScheduler(ctx, schedule, cfg.XKCD.Jitter)
+	}
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -102,6 +116,18 @@ func run(cfg config.Config, log *slog.Logger) error {
 	return nil
 }
 
+// makeSchedule prefers the cron expression to the check period; neither
+// set disables scheduled updates.
+func makeSchedule(cfg config.XKCD) (core.Schedule, error) {
+	if cfg.Schedule != "" {
+		return core.ParseSchedule(cfg.Schedule)
+	}
+	if cfg.CheckPeriod > 0 {
+		return core.Every(cfg.CheckPeriod), nil
+	}
+	return nil, nil
+}
+
 func mustMakeLogger(logLevel string) *slog.Logger {
 	var level slog.Level
 	switch logLevel {
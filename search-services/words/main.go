@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"google.golang.org/grpc"
@@ -18,14 +23,26 @@ import (
 	normalize "yadro.com/course/words/words"
 )
 
-const maxPhraseLen = 4096
+const (
+	maxPhraseLen = 4096
+
+	// Documents normalized in batches or streams, like comic transcripts,
+	// may be longer than search phrases.
+	maxDocumentLen = 64 << 10
+	maxBatchSize   = 1000
+)
 
 type server struct {
 	wordspb.UnimplementedWordsServer
+	synonyms *normalize.Dictionary
+	profiles map[string]*normalize.Pipeline
 }
 
 type Config struct {
-	GRPCPort string `yaml:"grpc_port" env:"WORDS_GRPC_PORT" env-default:"8080"`
+	GRPCPort       string                       `yaml:"grpc_port"       env:"WORDS_GRPC_PORT"       env-default:"8080"`
+	SynonymsFile   string                       `yaml:"synonyms_file"   env:"WORDS_SYNONYMS_FILE"`
+	SynonymsReload time.Duration                `yaml:"synonyms_reload" env:"WORDS_SYNONYMS_RELOAD" env-default:"10s"`
+	Profiles       map[string]normalize.Profile `yaml:"profiles"`
 }
 
 func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
@@ -39,41 +56,247 @@ func (s *server) Norm(_ context.Context, in *wordspb.WordsRequest) (*wordspb.Wor
 	if len(in.Phrase) > maxPhraseLen {
 		return nil, status.Error(codes.ResourceExhausted, "request exceeds 4 KiB")
 	}
-	words := normalize.Normalize(in.Phrase)
-	return &wordspb.WordsReply{Words: words}, nil
+	return s.norm(in)
 }
 
-func main() {
-	var cfgPath string
-	flag.StringVar(&cfgPath, "config", "", "path to config file")
-	flag.Parse()
+func (s *server) NormBatch(_ context.Context, in *wordspb.BatchRequest) (*wordspb.BatchReply, error) {
+	if in == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	if len(in.Phrases) > maxBatchSize {
+		return nil, status.Errorf(codes.ResourceExhausted, "batch exceeds %d phrases", maxBatchSize)
+	}
+	reply := &wordspb.BatchReply{Replies: make([]*wordspb.WordsReply, 0, len(in.Phrases))}
+	for i, req := range in.Phrases {
+		if len(req.GetPhrase()) > maxDocumentLen {
+			return nil, status.Errorf(codes.ResourceExhausted, "phrase %d exceeds 64 KiB", i)
+		}
+		r, err := s.norm(req)
+		if err != nil {
+			return nil, err
+		}
+		reply.Replies = append(reply.Replies, r)
+	}
+	return reply, nil
+}
+
+func (s *server) NormStream(stream wordspb.Words_NormStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(req.GetPhrase()) > maxDocumentLen {
+			return status.Error(codes.ResourceExhausted, "phrase exceeds 64 KiB")
+		}
+		reply, err := s.norm(req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
 
+func (s *server) norm(in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
+	if in == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	pipeline, err := s.pipeline(in.Profile)
+	if err != nil {
+		return nil, err
+	}
+	words, lang, err := pipeline.Analyze(in.Phrase, in.Language)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	reply := &wordspb.WordsReply{
+		Words:     make([]string, 0, len(words)),
+		Positions: make([]int32, 0, len(words)),
+		Language:  lang,
+		Tokens:    make([]*wordspb.Token, 0, len(words)),
+	}
+	for _, w := range words {
+		reply.Tokens = append(reply.Tokens, &wordspb.Token{
+			Surface:  w.Surface,
+			Stem:     w.Stem,
+			Position: int32(w.Pos),
+			Start:    int32(w.Start),
+			End:      int32(w.End),
+			StopWord: w.StopWord,
+		})
+		if w.Stem == "" {
+			continue
+		}
+		reply.Words = append(reply.Words, w.Stem)
+		reply.Positions = append(reply.Positions, int32(w.Pos))
+	}
+	return reply, nil
+}
+
+// pipeline returns the pipeline of the requested profile.
+func (s *server) pipeline(profile string) (*normalize.Pipeline, error) {
+	if profile == "" {
+		profile = normalize.DefaultProfile
+	}
+	if p, ok := s.profiles[profile]; ok {
+		return p, nil
+	}
+	if profile == normalize.DefaultProfile {
+		return normalize.DefaultPipeline(), nil
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "%v: %q", normalize.ErrUnknownProfile, profile)
+}
+
+func (s *server) Version(_ context.Context, in *wordspb.VersionRequest) (*wordspb.VersionReply, error) {
+	pipeline, err := s.pipeline(in.GetProfile())
+	if err != nil {
+		return nil, err
+	}
+	return &wordspb.VersionReply{Version: pipeline.Version()}, nil
+}
+
+func (s *server) Expand(_ context.Context, in *wordspb.WordsRequest) (*wordspb.ExpandReply, error) {
+	if in == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	if len(in.Phrase) > maxPhraseLen {
+		return nil, status.Error(codes.ResourceExhausted, "request exceeds 4 KiB")
+	}
+	pipeline, err := s.pipeline(in.Profile)
+	if err != nil {
+		return nil, err
+	}
+	tokens, _, err := pipeline.Tokenize(in.Phrase, in.Language)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	reply := &wordspb.ExpandReply{}
+	if s.synonyms == nil {
+		return reply, nil
+	}
+	for _, e := range s.synonyms.Expand(pipeline, tokens) {
+		positions := make([]int32, 0, len(e.Positions))
+		for _, p := range e.Positions {
+			positions = append(positions, int32(p))
+		}
+		reply.Expansions = append(reply.Expansions, &wordspb.Expansion{
+			Stem:      e.Stem,
+			Terms:     e.Terms,
+			Positions: positions,
+		})
+	}
+	return reply, nil
+}
+
+func buildConfig(path string) (Config, error) {
 	var cfg Config
-	if cfgPath != "" {
-		if err := cleanenv.ReadConfig(cfgPath, &cfg); err != nil {
-			log.Fatalf("failed to read config file: %v", err)
+	if path != "" {
+		if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+		cfg.SynonymsFile = relativeTo(path, cfg.SynonymsFile)
+		for name, profile := range cfg.Profiles {
+			switch profile.StopWords {
+			case "", normalize.StopWordsBuiltin, normalize.StopWordsNone:
+			default:
+				profile.StopWords = relativeTo(path, profile.StopWords)
+				cfg.Profiles[name] = profile
+			}
 		}
 	}
 	if err := cleanenv.ReadEnv(&cfg); err != nil {
-		log.Fatalf("failed to read env: %v", err)
+		return Config{}, fmt.Errorf("failed to read env: %w", err)
 	}
+	return cfg, nil
+}
+
+// relativeTo resolves a file name from the config file against its directory.
+func relativeTo(configPath, name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(configPath), name)
+}
 
-	addr := cfg.GRPCPort
+func listenAddr(port string) (net.Listener, string, error) {
+	addr := port
 	if !strings.HasPrefix(addr, ":") {
 		addr = ":" + addr
 	}
-
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return nil, "", err
 	}
+	return lis, addr, nil
+}
+
+func runServer(lis net.Listener) error {
+	return serve(lis, &server{})
+}
 
+func serve(lis net.Listener, srv *server) error {
 	s := grpc.NewServer()
-	wordspb.RegisterWordsServer(s, &server{})
+	wordspb.RegisterWordsServer(s, srv)
 	reflection.Register(s)
+	return s.Serve(lis)
+}
+
+// watchSynonyms reloads the dictionary whenever its file changes; a broken
+// file leaves the previous rules in place.
+func watchSynonyms(dict *normalize.Dictionary, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reloaded, err := dict.Reload(path)
+		if err != nil {
+			log.Printf("failed to reload synonyms from %s: %v", path, err)
+			continue
+		}
+		if reloaded {
+			log.Printf("synonyms reloaded from %s: %d rules", path, dict.Len())
+		}
+	}
+}
+
+func main() {
+	var cfgPath string
+	flag.StringVar(&cfgPath, "config", "", "path to config file")
+	flag.Parse()
+
+	cfg, err := buildConfig(cfgPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	profiles, err := normalize.NewPipelines(cfg.Profiles)
+	if err != nil {
+		log.Fatalf("failed to build normalization profiles: %v", err)
+	}
+
+	synonyms := normalize.NewDictionary()
+	if cfg.SynonymsFile != "" {
+		if err := synonyms.Load(cfg.SynonymsFile); err != nil {
+			log.Printf("failed to load synonyms: %v", err)
+		} else {
+			log.Printf("synonyms loaded from %s: %d rules", cfg.SynonymsFile, synonyms.Len())
+		}
+		if cfg.SynonymsReload > 0 {
+			go watchSynonyms(synonyms, cfg.SynonymsFile, cfg.SynonymsReload)
+		}
+	}
+
+	lis, addr, err := listenAddr(cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
 
 	log.Printf("words service listening on %s", addr)
-	if err := s.Serve(lis); err != nil {
+	if err := serve(lis, &server{synonyms: synonyms, profiles: profiles}); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
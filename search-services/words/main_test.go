@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	wordspb "yadro.com/course/proto/words"
+	normalize "yadro.com/course/words/words"
 )
 
 func TestNorm_EmptyPhrase(t *testing.T) {
@@ -50,6 +52,24 @@ func TestNorm_MultipleWordsWithPunctuation(t *testing.T) {
 	}
 }
 
+func TestNorm_Tokens(t *testing.T) {
+	s := &server{}
+	resp, err := s.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "Bobby and Tables"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Words) != 2 || len(resp.Tokens) != 3 {
+		t.Fatalf("expected 2 words and 3 tokens, got %v and %v", resp.Words, resp.Tokens)
+	}
+	and, tables := resp.Tokens[1], resp.Tokens[2]
+	if !and.StopWord || and.Stem != "" || and.Surface != "and" {
+		t.Fatalf("unexpected stop word token: %v", and)
+	}
+	if tables.Surface != "Tables" || tables.Stem != "tabl" || tables.Start != 10 || tables.End != 16 || tables.Position != 2 {
+		t.Fatalf("unexpected token: %v", tables)
+	}
+}
+
 func TestNorm_ErrorCodes(t *testing.T) {
 	s := &server{}
 
@@ -192,4 +212,133 @@ func TestGRPC_EndToEnd(t *testing.T) {
 	if len(resp.Words) == 0 {
 		t.Fatalf("expected non empty words slice")
 	}
+
+	stream, err := client.NormStream(ctx)
+	if err != nil {
+		t.Fatalf("NormStream failed: %v", err)
+	}
+	phrases := []string{"cats", "", "dogs and mice"}
+	for _, p := range phrases {
+		if err := stream.Send(&wordspb.WordsRequest{Phrase: p}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+	for i, want := range []int{1, 0, 2} {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv %d failed: %v", i, err)
+		}
+		if len(reply.Words) != want {
+			t.Fatalf("reply %d: expected %d words, got %v", i, want, reply.Words)
+		}
+	}
+}
+
+func TestNormBatch(t *testing.T) {
+	s := &server{}
+	long := strings.Repeat("comic ", maxPhraseLen)
+	resp, err := s.NormBatch(context.Background(), &wordspb.BatchRequest{Phrases: []*wordspb.WordsRequest{
+		{Phrase: "Hello, world!"}, {Phrase: long},
+	}})
+	if err != nil {
+		t.Fatalf("NormBatch failed: %v", err)
+	}
+	if len(resp.Replies) != 2 || len(resp.Replies[0].Words) != 2 || len(resp.Replies[1].Words) != maxPhraseLen {
+		t.Fatalf("unexpected replies: %d", len(resp.Replies))
+	}
+
+	_, err = s.NormBatch(context.Background(), &wordspb.BatchRequest{Phrases: []*wordspb.WordsRequest{
+		{Phrase: strings.Repeat("a", maxDocumentLen+1)},
+	}})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestExpand_NoDictionary(t *testing.T) {
+	s := &server{}
+	resp, err := s.Expand(context.Background(), &wordspb.WordsRequest{Phrase: "car"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Expansions) != 0 {
+		t.Fatalf("expected no expansions, got %d", len(resp.Expansions))
+	}
+}
+
+func TestExpand_Profiles(t *testing.T) {
+	cfg, err := buildConfig("config.yaml")
+	if err != nil {
+		t.Fatalf("buildConfig failed: %v", err)
+	}
+	profiles, err := normalize.NewPipelines(cfg.Profiles)
+	if err != nil {
+		t.Fatalf("NewPipelines failed: %v", err)
+	}
+	synonyms := normalize.NewDictionary()
+	if err := synonyms.Load(cfg.SynonymsFile); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s := &server{synonyms: synonyms, profiles: profiles}
+
+	cases := []struct {
+		profile, phrase, want string
+	}{
+		{"", "cars", "automobil"},
+		{"exact", "car", "automobile"},
+		{"strict", "AI's", "artifici"},
+	}
+	for _, c := range cases {
+		resp, err := s.Expand(context.Background(), &wordspb.WordsRequest{Phrase: c.phrase, Profile: c.profile})
+		if err != nil {
+			t.Fatalf("%q: Expand failed: %v", c.profile, err)
+		}
+		if len(resp.Expansions) == 0 || resp.Expansions[0].Terms[0] != c.want {
+			t.Fatalf("%q: expected %q first, got %v", c.profile, c.want, resp.Expansions)
+		}
+	}
+}
+
+func TestNorm_Profiles(t *testing.T) {
+	cfg, err := buildConfig("config.yaml")
+	if err != nil {
+		t.Fatalf("buildConfig failed: %v", err)
+	}
+	profiles, err := normalize.NewPipelines(cfg.Profiles)
+	if err != nil {
+		t.Fatalf("NewPipelines failed: %v", err)
+	}
+	s := &server{profiles: profiles}
+
+	resp, err := s.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "The tables", Profile: "exact"})
+	if err != nil {
+		t.Fatalf("Norm failed: %v", err)
+	}
+	if len(resp.Words) != 2 || resp.Words[1] != "tables" {
+		t.Fatalf("unexpected words: %v", resp.Words)
+	}
+
+	_, err = s.Norm(context.Background(), &wordspb.WordsRequest{Phrase: "tables", Profile: "missing"})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+
+	exact, err := s.Version(context.Background(), &wordspb.VersionRequest{Profile: "exact"})
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	def, err := s.Version(context.Background(), &wordspb.VersionRequest{})
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if exact.Version == "" || exact.Version == def.Version {
+		t.Fatalf("unexpected versions %q and %q", exact.Version, def.Version)
+	}
+	_, err = s.Version(context.Background(), &wordspb.VersionRequest{Profile: "missing"})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
 }
@@ -1,31 +1,158 @@
 package words
 
 import (
-	"regexp"
+	"errors"
 	"strings"
+	"unicode"
 
 	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
 )
 
-var wordRe = regexp.MustCompile(`[a-z0-9]+`)
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// DefaultTokenPattern matches words in any script: letters with their
+// combining marks and digits.
+const DefaultTokenPattern = `[\p{L}\p{M}\p{N}]+`
+
+type language struct {
+	code     string
+	script   *unicode.RangeTable
+	stem     func(word string, stemStopWords bool) string
+	stopWord func(word string) bool
+}
+
+// languages lists the supported languages; the first one is the default for
+// phrases without letters of any known script.
+var languages = []language{
+	{code: English, script: unicode.Latin, stem: english.Stem, stopWord: english.IsStopWord},
+	{code: Russian, script: unicode.Cyrillic, stem: russian.Stem, stopWord: russian.IsStopWord},
+}
+
+type Token struct {
+	Stem string
+	Pos  int
+}
+
+// Word is a word of a phrase as written, with its byte offsets in the phrase.
+// Stem is empty for words dropped by the pipeline, StopWord tells stop words
+// from words dropped by length, number or custom filters.
+type Word struct {
+	Surface  string
+	Stem     string
+	Pos      int
+	Start    int
+	End      int
+	StopWord bool
+}
 
 func Normalize(phrase string) []string {
-	if phrase == "" {
+	tokens := Tokenize(phrase)
+	if tokens == nil {
 		return nil
 	}
-	lc := strings.ToLower(phrase)
-	tokens := wordRe.FindAllString(lc, -1)
-
 	out := make([]string, 0, len(tokens))
 	for _, t := range tokens {
-		if t == "" || english.IsStopWord(t) {
-			continue
+		out = append(out, t.Stem)
+	}
+	return out
+}
+
+// Tokenize returns the stems of the phrase together with the ordinal position
+// of the source word, stop words included, so gaps between stems are kept.
+// The language is detected from the phrase.
+func Tokenize(phrase string) []Token {
+	tokens, _, _ := TokenizeIn(phrase, "")
+	return tokens
+}
+
+// TokenizeIn is Tokenize in the given language, detected when empty; it also
+// returns the language used. Words written in a script of another language,
+// like English names in a Russian phrase, are stemmed in that language.
+func TokenizeIn(phrase, lang string) ([]Token, string, error) {
+	return DefaultPipeline().Tokenize(phrase, lang)
+}
+
+func resolveLanguage(phrase, lang string) (language, error) {
+	if lang == "" {
+		return detect(phrase), nil
+	}
+	l, ok := lookupLanguage(lang)
+	if !ok {
+		return language{}, ErrUnknownLanguage
+	}
+	return l, nil
+}
+
+// wordLanguage returns the language a word of a phrase in base is stemmed in.
+func wordLanguage(word string, base language) language {
+	if inScript(word, base.script) {
+		return base
+	}
+	return scriptLanguage(word, base)
+}
+
+// Detect returns the language most letters of the phrase are written in.
+func Detect(phrase string) string {
+	return detect(phrase).code
+}
+
+func detect(phrase string) language {
+	counts := make([]int, len(languages))
+	for _, r := range phrase {
+		for i, l := range languages {
+			if unicode.Is(l.script, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+	best := 0
+	for i := range counts {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+	return languages[best]
+}
+
+func lookupLanguage(code string) (language, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, l := range languages {
+		if l.code == code {
+			return l, true
+		}
+	}
+	return language{}, false
+}
+
+func inScript(word string, script *unicode.RangeTable) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) && !unicode.Is(script, r) {
+			return false
 		}
-		stem := english.Stem(t, true)
-		if stem == "" {
+	}
+	return true
+}
+
+// scriptLanguage returns the language of the first letter of word, or def
+// when the script is not a known one.
+func scriptLanguage(word string, def language) language {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
 			continue
 		}
-		out = append(out, stem)
+		for _, l := range languages {
+			if unicode.Is(l.script, r) {
+				return l
+			}
+		}
+		break
 	}
-	return out
+	return def
 }
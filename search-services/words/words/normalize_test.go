@@ -0,0 +1,55 @@
+package words
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeIn(t *testing.T) {
+	cases := []struct {
+		phrase, lang string
+		want         []Token
+		wantLang     string
+	}{
+		{
+			phrase:   "Linux kernels",
+			want:     []Token{{Stem: "linux", Pos: 0}, {Stem: "kernel", Pos: 1}},
+			wantLang: English,
+		},
+		{
+			phrase:   "Кошки и собаки",
+			want:     []Token{{Stem: "кошк", Pos: 0}, {Stem: "собак", Pos: 2}},
+			wantLang: Russian,
+		},
+		{
+			phrase:   "ядро Linux, ёлки",
+			want:     []Token{{Stem: "ядр", Pos: 0}, {Stem: "linux", Pos: 1}, {Stem: "елк", Pos: 2}},
+			wantLang: Russian,
+		},
+		{
+			phrase:   "café crème",
+			want:     []Token{{Stem: "café", Pos: 0}, {Stem: "crème", Pos: 1}},
+			wantLang: English,
+		},
+		{
+			phrase:   "42",
+			lang:     "RU",
+			want:     []Token{{Stem: "42", Pos: 0}},
+			wantLang: Russian,
+		},
+	}
+	for _, tc := range cases {
+		got, lang, err := TokenizeIn(tc.phrase, tc.lang)
+		if err != nil {
+			t.Fatalf("TokenizeIn(%q) failed: %v", tc.phrase, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) || lang != tc.wantLang {
+			t.Fatalf("TokenizeIn(%q) = %v, %q; want %v, %q", tc.phrase, got, lang, tc.want, tc.wantLang)
+		}
+	}
+
+	if _, _, err := TokenizeIn("hallo", "de"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Fatalf("expected ErrUnknownLanguage, got %v", err)
+	}
+}
@@ -0,0 +1,351 @@
+package words
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const DefaultProfile = "default"
+
+// Revision is the revision of the normalization code, part of every
+// pipeline version. Bump it whenever tokenizing, stemming or the built-in
+// stop words change the stems of the same text.
+const Revision = 1
+
+const (
+	StopWordsBuiltin = "builtin"
+	StopWordsNone    = "none"
+
+	StemmerSnowball = "snowball"
+	StemmerNone     = "none"
+
+	NumbersKeep      = "keep"
+	NumbersDrop      = "drop"
+	NumbersNormalize = "normalize"
+)
+
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile configures a normalization pipeline. Empty fields keep the default
+// behaviour: words of any script, no length limits, built-in stop words of
+// the phrase language, snowball stemming and numbers kept as they are.
+type Profile struct {
+	// TokenPattern is the regular expression matching words in the
+	// lowercased phrase.
+	TokenPattern string `yaml:"token_pattern"`
+	// MinLength and MaxLength bound word lengths in runes, 0 means no bound.
+	MinLength int `yaml:"min_length"`
+	MaxLength int `yaml:"max_length"`
+	// StopWords is "builtin", "none" or the path of a file with one stop
+	// word per line.
+	StopWords string `yaml:"stop_words"`
+	// Stemmer is "snowball" or "none".
+	Stemmer string `yaml:"stemmer"`
+	// Numbers is "keep", "drop" or "normalize", which strips leading zeros.
+	Numbers string   `yaml:"numbers"`
+	Filters []Filter `yaml:"filters"`
+}
+
+// Filter is a custom step applied to every word before stop words and
+// stemming: words matching Drop are removed, matches of Replace are replaced
+// with With.
+type Filter struct {
+	Drop    string `yaml:"drop"`
+	Replace string `yaml:"replace"`
+	With    string `yaml:"with"`
+}
+
+// Pipeline is a compiled profile.
+type Pipeline struct {
+	tokenRe   *regexp.Regexp
+	minLength int
+	maxLength int
+	stopWords func(l language, word string) bool
+	stem      bool
+	numbers   string
+	filters   []filter
+	version   string
+}
+
+type filter struct {
+	re   *regexp.Regexp
+	drop bool
+	with string
+}
+
+var defaultPipeline = func() *Pipeline {
+	p, err := NewPipeline(Profile{})
+	if err != nil {
+		panic(err)
+	}
+	return p
+}()
+
+// DefaultPipeline returns the pipeline of the empty profile.
+func DefaultPipeline() *Pipeline {
+	return defaultPipeline
+}
+
+func NewPipeline(profile Profile) (*Pipeline, error) {
+	pattern := profile.TokenPattern
+	if pattern == "" {
+		pattern = DefaultTokenPattern
+	}
+	tokenRe, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("token pattern: %w", err)
+	}
+	if profile.MinLength < 0 || profile.MaxLength < 0 ||
+		(profile.MaxLength > 0 && profile.MaxLength < profile.MinLength) {
+		return nil, fmt.Errorf("bad token length bounds %d..%d", profile.MinLength, profile.MaxLength)
+	}
+
+	p := &Pipeline{
+		tokenRe:   tokenRe,
+		minLength: profile.MinLength,
+		maxLength: profile.MaxLength,
+	}
+
+	stopWords := []string{profile.StopWords}
+	switch profile.StopWords {
+	case "", StopWordsBuiltin:
+		p.stopWords = func(l language, word string) bool { return l.stopWord(word) }
+		stopWords = []string{StopWordsBuiltin}
+	case StopWordsNone:
+		p.stopWords = func(language, string) bool { return false }
+	default:
+		words, err := loadStopWords(profile.StopWords)
+		if err != nil {
+			return nil, err
+		}
+		// the version follows the words of the file, not its path
+		stopWords = make([]string, 0, len(words))
+		for w := range words {
+			stopWords = append(stopWords, w)
+		}
+		slices.Sort(stopWords)
+		p.stopWords = func(_ language, word string) bool {
+			_, ok := words[word]
+			return ok
+		}
+	}
+
+	switch profile.Stemmer {
+	case "", StemmerSnowball:
+		p.stem = true
+	case StemmerNone:
+	default:
+		return nil, fmt.Errorf("unknown stemmer %q", profile.Stemmer)
+	}
+
+	switch profile.Numbers {
+	case "", NumbersKeep:
+		p.numbers = NumbersKeep
+	case NumbersDrop, NumbersNormalize:
+		p.numbers = profile.Numbers
+	default:
+		return nil, fmt.Errorf("unknown numbers handling %q", profile.Numbers)
+	}
+
+	for i, f := range profile.Filters {
+		switch {
+		case f.Drop != "" && f.Replace == "":
+			re, err := regexp.Compile(f.Drop)
+			if err != nil {
+				return nil, fmt.Errorf("filter %d: %w", i, err)
+			}
+			p.filters = append(p.filters, filter{re: re, drop: true})
+		case f.Replace != "" && f.Drop == "":
+			re, err := regexp.Compile(f.Replace)
+			if err != nil {
+				return nil, fmt.Errorf("filter %d: %w", i, err)
+			}
+			p.filters = append(p.filters, filter{re: re, with: f.With})
+		default:
+			return nil, fmt.Errorf("filter %d: exactly one of drop and replace must be set", i)
+		}
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\n%q\n%d\n%d\n%q\n%t\n%q\n%q\n",
+		Revision, pattern, p.minLength, p.maxLength, stopWords, p.stem, p.numbers, profile.Filters)
+	p.version = hex.EncodeToString(h.Sum(nil))[:16]
+	return p, nil
+}
+
+// Version identifies the stems the pipeline produces: it changes with the
+// settings of the profile and with Revision, so text normalized by two
+// pipelines of the same version has the same stems.
+func (p *Pipeline) Version() string {
+	return p.version
+}
+
+// NewPipelines compiles named profiles. The default profile is added with
+// the default settings unless configured.
+func NewPipelines(profiles map[string]Profile) (map[string]*Pipeline, error) {
+	out := make(map[string]*Pipeline, len(profiles)+1)
+	for name, profile := range profiles {
+		p, err := NewPipeline(profile)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		out[name] = p
+	}
+	if _, ok := out[DefaultProfile]; !ok {
+		out[DefaultProfile] = defaultPipeline
+	}
+	return out, nil
+}
+
+// Tokenize returns the stems of the phrase together with the ordinal position
+// of the source word, dropped words included, so gaps between stems are
+// kept. The language is detected when lang is empty and returned.
+func (p *Pipeline) Tokenize(phrase, lang string) ([]Token, string, error) {
+	words, code, err := p.Analyze(phrase, lang)
+	if err != nil {
+		return nil, "", err
+	}
+	if words == nil {
+		return nil, code, nil
+	}
+	out := make([]Token, 0, len(words))
+	for _, w := range words {
+		if w.Stem != "" {
+			out = append(out, Token{Stem: w.Stem, Pos: w.Pos})
+		}
+	}
+	return out, code, nil
+}
+
+// Analyze returns every word of the phrase matched by the token pattern,
+// with the stem of the words kept and the byte offsets of the word in the
+// phrase.
+func (p *Pipeline) Analyze(phrase, lang string) ([]Word, string, error) {
+	base, err := resolveLanguage(phrase, lang)
+	if err != nil {
+		return nil, "", err
+	}
+	if phrase == "" {
+		return nil, base.code, nil
+	}
+
+	lc, offsets := foldCase(phrase)
+	spans := p.tokenRe.FindAllStringIndex(lc, -1)
+
+	out := make([]Word, 0, len(spans))
+	for pos, span := range spans {
+		start, end := offsets[span[0]], offsets[span[1]]
+		word := Word{Surface: phrase[start:end], Pos: pos, Start: start, End: end}
+		out = append(out, word)
+
+		w, ok := p.filter(lc[span[0]:span[1]])
+		if !ok {
+			continue
+		}
+		l := wordLanguage(w, base)
+		if p.stopWords(l, w) {
+			out[len(out)-1].StopWord = true
+			continue
+		}
+		stem := w
+		if p.stem && !isNumber(w) {
+			stem = l.stem(w, true)
+		}
+		out[len(out)-1].Stem = stem
+	}
+	return out, base.code, nil
+}
+
+// foldCase lowercases the phrase and folds ё into е. It also returns, for
+// every byte offset of the result and its end, the matching offset in the
+// phrase, as lowercasing may change the length of some letters.
+func foldCase(phrase string) (string, []int) {
+	var b strings.Builder
+	b.Grow(len(phrase))
+	offsets := make([]int, 0, len(phrase)+1)
+	for i, r := range phrase {
+		r = unicode.ToLower(r)
+		if r == 'ё' {
+			r = 'е'
+		}
+		n, _ := b.WriteRune(r)
+		for range n {
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(phrase))
+	return b.String(), offsets
+}
+
+// filter runs a word through the custom filters, length bounds and number
+// handling and reports whether it is kept.
+func (p *Pipeline) filter(w string) (string, bool) {
+	for _, f := range p.filters {
+		if f.drop {
+			if f.re.MatchString(w) {
+				return "", false
+			}
+			continue
+		}
+		w = f.re.ReplaceAllString(w, f.with)
+	}
+	if w == "" {
+		return "", false
+	}
+	if n := utf8.RuneCountInString(w); n < p.minLength || (p.maxLength > 0 && n > p.maxLength) {
+		return "", false
+	}
+	if isNumber(w) {
+		switch p.numbers {
+		case NumbersDrop:
+			return "", false
+		case NumbersNormalize:
+			if trimmed := strings.TrimLeft(w, "0"); trimmed != "" {
+				w = trimmed
+			} else {
+				w = "0"
+			}
+		}
+	}
+	return w, true
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return w != ""
+}
+
+func loadStopWords(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("stop words: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	words := make(map[string]struct{})
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		w := strings.ToLower(strings.TrimSpace(sc.Text()))
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		words[w] = struct{}{}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("stop words: %w", err)
+	}
+	return words, nil
+}
@@ -0,0 +1,168 @@
+package words
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func stems(t *testing.T, p *Pipeline, phrase string) []Token {
+	t.Helper()
+	tokens, _, err := p.Tokenize(phrase, "")
+	if err != nil {
+		t.Fatalf("Tokenize(%q) failed: %v", phrase, err)
+	}
+	return tokens
+}
+
+func TestPipeline_Profiles(t *testing.T) {
+	stopWords := filepath.Join(t.TempDir(), "stop.txt")
+	if err := os.WriteFile(stopWords, []byte("# custom\nbobby\n"), 0o600); err != nil {
+		t.Fatalf("write stop words: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		profile Profile
+		phrase  string
+		want    []Token
+	}{
+		{
+			name:   "default",
+			phrase: "The 007 tables of a Bobby",
+			want:   []Token{{Stem: "007", Pos: 1}, {Stem: "tabl", Pos: 2}, {Stem: "bobbi", Pos: 5}},
+		},
+		{
+			name:    "no stemming and stop words",
+			profile: Profile{StopWords: StopWordsNone, Stemmer: StemmerNone},
+			phrase:  "The tables",
+			want:    []Token{{Stem: "the", Pos: 0}, {Stem: "tables", Pos: 1}},
+		},
+		{
+			name:    "stop words file",
+			profile: Profile{StopWords: stopWords},
+			phrase:  "the bobby tables",
+			want:    []Token{{Stem: "the", Pos: 0}, {Stem: "tabl", Pos: 2}},
+		},
+		{
+			name:    "lengths and numbers",
+			profile: Profile{MinLength: 2, MaxLength: 5, Numbers: NumbersNormalize},
+			phrase:  "x 007 cat tables",
+			want:    []Token{{Stem: "7", Pos: 1}, {Stem: "cat", Pos: 2}},
+		},
+		{
+			name:    "dropped numbers",
+			profile: Profile{Numbers: NumbersDrop},
+			phrase:  "route 66",
+			want:    []Token{{Stem: "rout", Pos: 0}},
+		},
+		{
+			name: "pattern and filters",
+			profile: Profile{
+				TokenPattern: `[\p{L}']+`,
+				Filters:      []Filter{{Replace: `'s$`, With: ""}, {Drop: `^x+$`}},
+			},
+			phrase: "Bobby's xxx tables",
+			want:   []Token{{Stem: "bobbi", Pos: 0}, {Stem: "tabl", Pos: 2}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPipeline(tc.profile)
+			if err != nil {
+				t.Fatalf("NewPipeline failed: %v", err)
+			}
+			if got := stems(t, p, tc.phrase); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewPipeline_Errors(t *testing.T) {
+	for _, profile := range []Profile{
+		{TokenPattern: "["},
+		{MinLength: 5, MaxLength: 2},
+		{Stemmer: "porter"},
+		{Numbers: "spell"},
+		{Filters: []Filter{{Drop: "a", Replace: "b"}}},
+		{StopWords: "/nonexistent/stop.txt"},
+	} {
+		if _, err := NewPipeline(profile); err == nil {
+			t.Fatalf("expected error for %+v", profile)
+		}
+	}
+}
+
+func TestPipeline_Version(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write stop words: %v", err)
+		}
+		return path
+	}
+	version := func(profile Profile) string {
+		p, err := NewPipeline(profile)
+		if err != nil {
+			t.Fatalf("NewPipeline(%+v) failed: %v", profile, err)
+		}
+		return p.Version()
+	}
+
+	base := DefaultPipeline().Version()
+	if base == "" {
+		t.Fatal("expected a version of the default pipeline")
+	}
+	explicit := Profile{
+		TokenPattern: DefaultTokenPattern,
+		StopWords:    StopWordsBuiltin,
+		Stemmer:      StemmerSnowball,
+		Numbers:      NumbersKeep,
+	}
+	if v := version(explicit); v != base {
+		t.Fatalf("explicit defaults: expected %s, got %s", base, v)
+	}
+	if version(Profile{StopWords: write("a.txt", "bobby\ntables\n")}) !=
+		version(Profile{StopWords: write("b.txt", "# same\ntables\nbobby\n")}) {
+		t.Fatal("expected the same version for the same stop words")
+	}
+	for _, profile := range []Profile{
+		{Stemmer: StemmerNone},
+		{StopWords: StopWordsNone},
+		{StopWords: write("c.txt", "bobby\n")},
+		{MinLength: 2},
+		{Numbers: NumbersDrop},
+		{Filters: []Filter{{Replace: "'s$"}}},
+	} {
+		if v := version(profile); v == base {
+			t.Fatalf("expected another version for %+v", profile)
+		}
+	}
+}
+
+func TestPipeline_Analyze(t *testing.T) {
+	phrase := "The Kelvin, Ёлки!"
+	words, lang, err := DefaultPipeline().Analyze(phrase, "")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if lang != English {
+		t.Fatalf("expected English, got %q", lang)
+	}
+	want := []Word{
+		{Surface: "The", Pos: 0, Start: 0, End: 3, StopWord: true},
+		{Surface: "Kelvin", Stem: "kelvin", Pos: 1, Start: 4, End: 12},
+		{Surface: "Ёлки", Stem: "елк", Pos: 2, Start: 14, End: 22},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Fatalf("got %+v, want %+v", words, want)
+	}
+	for _, w := range words {
+		if phrase[w.Start:w.End] != w.Surface {
+			t.Fatalf("offsets of %q point at %q", w.Surface, phrase[w.Start:w.End])
+		}
+	}
+}
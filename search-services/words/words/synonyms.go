@@ -0,0 +1,232 @@
+package words
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Expansion is an alternative for a query stem: the stems of a synonym or of
+// a longer form, with their word positions when there are several.
+type Expansion struct {
+	Stem      string
+	Terms     []string
+	Positions []int
+}
+
+// Dictionary maps stems to their expansions. It is read from a text file in
+// the usual synonyms format, one rule per line:
+//
+//	car, automobile, auto         every word expands to the others
+//	ai => artificial intelligence the left side expands to the right side
+//
+// Empty lines and lines starting with # are ignored. Rules are normalized by
+// the pipeline of each lookup, so their stems match the stems of the phrase.
+// Only words normalizing to a single stem can be expanded; any phrase can be
+// an expansion.
+type Dictionary struct {
+	mu      sync.RWMutex
+	rules   []rule
+	entries map[*Pipeline]map[string][]Expansion
+	modTime time.Time
+}
+
+type rule struct {
+	from, to []string
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{entries: map[*Pipeline]map[string][]Expansion{}}
+}
+
+// Load replaces the dictionary with the rules of the file at path. A broken
+// file leaves the previous rules in place and is not retried by Reload until
+// it changes again.
+func (d *Dictionary) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	rules, err := parseRules(f)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.modTime = info.ModTime()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	d.rules = rules
+	d.entries = map[*Pipeline]map[string][]Expansion{}
+	return nil
+}
+
+// Reload loads the file again if it changed since the last load and reports
+// whether it did. A missing file keeps the current rules.
+func (d *Dictionary) Reload(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	d.mu.RLock()
+	same := info.ModTime().Equal(d.modTime)
+	d.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+	return true, d.Load(path)
+}
+
+// Len returns the number of rules.
+func (d *Dictionary) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.rules)
+}
+
+// Expand returns the expansions of every stem among tokens, each stem once.
+// The tokens and the rules are normalized by p.
+func (d *Dictionary) Expand(p *Pipeline, tokens []Token) []Expansion {
+	entries := d.entriesOf(p)
+
+	var out []Expansion
+	seen := make(map[string]struct{})
+	for _, t := range tokens {
+		if _, ok := seen[t.Stem]; ok {
+			continue
+		}
+		seen[t.Stem] = struct{}{}
+		out = append(out, entries[t.Stem]...)
+	}
+	return out
+}
+
+// entriesOf returns the rules normalized by p, normalizing them on first use.
+func (d *Dictionary) entriesOf(p *Pipeline) map[string][]Expansion {
+	d.mu.RLock()
+	entries, ok := d.entries[p]
+	d.mu.RUnlock()
+	if ok {
+		return entries
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entries, ok := d.entries[p]; ok {
+		return entries
+	}
+	entries = compileRules(d.rules, p)
+	d.entries[p] = entries
+	return entries
+}
+
+// ParseDictionary reads the rules of r and normalizes them by p.
+func ParseDictionary(r io.Reader, p *Pipeline) (map[string][]Expansion, error) {
+	rules, err := parseRules(r)
+	if err != nil {
+		return nil, err
+	}
+	return compileRules(rules, p), nil
+}
+
+func parseRules(r io.Reader) ([]rule, error) {
+	var rules []rule
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		left, right, oneWay := strings.Cut(line, "=>")
+		from := splitRule(left)
+		to := from
+		if oneWay {
+			to = splitRule(right)
+		}
+		if len(from) == 0 || len(to) == 0 || (!oneWay && len(from) < 2) {
+			return nil, fmt.Errorf("line %d: incomplete rule %q", n, line)
+		}
+		rules = append(rules, rule{from: from, to: to})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// compileRules maps the stems of the rule words, as p normalizes them, to
+// their expansions. Words p drops altogether are skipped.
+func compileRules(rules []rule, p *Pipeline) map[string][]Expansion {
+	entries := make(map[string][]Expansion)
+	add := func(from, to []Token) {
+		if len(from) != 1 || len(to) == 0 || sameStems(from, to) {
+			return
+		}
+		stem := from[0].Stem
+		e := Expansion{Stem: stem, Terms: make([]string, 0, len(to)), Positions: make([]int, 0, len(to))}
+		for _, t := range to {
+			e.Terms = append(e.Terms, t.Stem)
+			e.Positions = append(e.Positions, t.Pos)
+		}
+		for _, prev := range entries[stem] {
+			if strings.Join(prev.Terms, " ") == strings.Join(e.Terms, " ") {
+				return
+			}
+		}
+		entries[stem] = append(entries[stem], e)
+	}
+	tokenize := func(parts []string) [][]Token {
+		out := make([][]Token, 0, len(parts))
+		for _, part := range parts {
+			if tokens, _, err := p.Tokenize(part, ""); err == nil && len(tokens) > 0 {
+				out = append(out, tokens)
+			}
+		}
+		return out
+	}
+
+	for _, r := range rules {
+		to := tokenize(r.to)
+		for _, f := range tokenize(r.from) {
+			for _, t := range to {
+				add(f, t)
+			}
+		}
+	}
+	return entries
+}
+
+func splitRule(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+func sameStems(a, b []Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Stem != b[i].Stem {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package words
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDictionary(t *testing.T) {
+	entries, err := ParseDictionary(strings.NewReader(`
+# vehicles
+car, automobile
+AI => artificial intelligence
+`), DefaultPipeline())
+	if err != nil {
+		t.Fatalf("ParseDictionary failed: %v", err)
+	}
+
+	want := map[string][]Expansion{
+		"car":       {{Stem: "car", Terms: []string{"automobil"}, Positions: []int{0}}},
+		"automobil": {{Stem: "automobil", Terms: []string{"car"}, Positions: []int{0}}},
+		"ai":        {{Stem: "ai", Terms: []string{"artifici", "intellig"}, Positions: []int{0, 1}}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestParseDictionary_Incomplete(t *testing.T) {
+	if _, err := ParseDictionary(strings.NewReader("car\n"), DefaultPipeline()); err == nil {
+		t.Fatalf("expected error for a rule without alternatives")
+	}
+	if _, err := ParseDictionary(strings.NewReader("ai =>\n"), DefaultPipeline()); err == nil {
+		t.Fatalf("expected error for a rule without expansion")
+	}
+}
+
+func TestDictionary_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte("car, automobile\n"), 0o600); err != nil {
+		t.Fatalf("write synonyms: %v", err)
+	}
+
+	d := NewDictionary()
+	if err := d.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := d.Expand(DefaultPipeline(), Tokenize("red cars")); len(got) != 1 || got[0].Terms[0] != "automobil" {
+		t.Fatalf("unexpected expansion: %+v", got)
+	}
+
+	if reloaded, err := d.Reload(path); err != nil || reloaded {
+		t.Fatalf("unchanged file reloaded: %v, %v", reloaded, err)
+	}
+
+	if err := os.WriteFile(path, []byte("broken\n"), 0o600); err != nil {
+		t.Fatalf("write synonyms: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if _, err := d.Reload(path); err == nil {
+		t.Fatalf("expected error for a broken file")
+	}
+	if len(d.Expand(DefaultPipeline(), Tokenize("car"))) != 1 {
+		t.Fatalf("broken file dropped the previous rules")
+	}
+}
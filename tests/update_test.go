@@ -1,11 +1,14 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -14,16 +17,245 @@ import (
 )
 
 type UpdateStats struct {
-	WordsTotal    int `json:"words_total"`
-	WordsUnique   int `json:"words_unique"`
-	ComicsFetched int `json:"comics_fetched"`
-	ComicsTotal   int `json:"comics_total"`
+	WordsTotal     int `json:"words_total"`
+	WordsUnique    int `json:"words_unique"`
+	ComicsFetched  int `json:"comics_fetched"`
+	ComicsNotFound int `json:"comics_not_found"`
+	ComicsFailed   int `json:"comics_failed"`
+	ComicsTotal    int `json:"comics_total"`
 }
 
 type UpdateStatus struct {
 	Status string `json:"status"`
 }
 
+type UpdateJob struct {
+	ID           int64    `json:"id"`
+	Trigger      string   `json:"trigger"`
+	State        string   `json:"state"`
+	Total        int      `json:"total"`
+	Done         int      `json:"done"`
+	Placeholders int      `json:"placeholders"`
+	Errors       []string `json:"errors"`
+	DurationMS   int64    `json:"duration_ms"`
+}
+
+type UpdateReply struct {
+	Status string    `json:"status"`
+	Job    UpdateJob `json:"job"`
+}
+
+func TestStatusStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address+"/api/db/status/stream", nil)
+	require.NoError(t, err, "cannot make request")
+	resp, err := client.Do(req)
+	require.NoError(t, err, "could not open status stream")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var status UpdateStatus
+		require.NoError(t, json.Unmarshal([]byte(data), &status), "cannot decode")
+		require.Contains(t, []string{"idle", "running"}, status.Status)
+		return
+	}
+	t.Fatalf("no status event: %v", scanner.Err())
+}
+
+func TestUpdateJobs(t *testing.T) {
+	prepare(t)
+	token := login(t)
+	status, err := update(token)
+	require.NoError(t, err, "could not update")
+	require.Equal(t, "started", status)
+
+	resp, err := client.Get(address + "/api/db/jobs?limit=1")
+	require.NoError(t, err, "could not list jobs")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var reply struct {
+		Jobs []UpdateJob `json:"jobs"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+	require.Len(t, reply.Jobs, 1)
+	last := reply.Jobs[0]
+	require.Equal(t, "succeeded", last.State)
+	require.True(t, last.Total > 3000, "the job must fetch all comics")
+	require.True(t, last.DurationMS > 0)
+
+	j, err := job(last.ID)
+	require.NoError(t, err)
+	require.Equal(t, last.ID, j.ID)
+
+	resp, err = client.Get(address + "/api/db/jobs/999999999")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	prepare(t)
+}
+
+func TestUpdateCancel(t *testing.T) {
+	prepare(t)
+	token := login(t)
+	cancel := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, address+"/api/db/update/cancel", nil)
+		require.NoError(t, err, "cannot make request")
+		req.Header.Add("Authorization", "Token "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err, "could not send cancel command")
+		return resp
+	}
+
+	resp := cancel()
+	resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode, "nothing to cancel")
+
+	req, err := http.NewRequest(http.MethodPost, address+"/api/db/update", nil)
+	require.NoError(t, err, "cannot make request")
+	req.Header.Add("Authorization", "Token "+token)
+	resp, err = client.Do(req)
+	require.NoError(t, err, "could not send update command")
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	time.Sleep(time.Second)
+
+	resp = cancel()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var j UpdateJob
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&j), "cannot decode")
+	require.Equal(t, "cancelled", j.State)
+	require.True(t, j.Done < j.Total, "the job must stop before fetching everything")
+
+	st, err := status()
+	require.NoError(t, err)
+	require.Equal(t, "idle", st)
+	stored := stats(t)
+	require.Equal(t, j.Done, stored.ComicsFetched)
+	require.Equal(t, j.Placeholders, stored.ComicsNotFound)
+
+	prepare(t)
+}
+
+func TestRefetch(t *testing.T) {
+	prepare(t)
+	token := login(t)
+	refetch := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, address+"/api/db/refetch", bytes.NewBufferString(body))
+		require.NoError(t, err, "cannot make request")
+		req.Header.Add("Authorization", "Token "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err, "could not send refetch command")
+		return resp
+	}
+
+	resp := refetch(`{}`)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "nothing to refetch")
+
+	resp = refetch(`{"ids": [` + strings.Repeat("1, ", 1<<20) + `1]}`)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode, "body too large")
+
+	resp = refetch(`{"ids": [404, 1], "ranges": [{"from": 1, "to": 10}]}`)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var reply UpdateReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+	require.Equal(t, "started", reply.Status)
+
+	var j UpdateJob
+	for {
+		var err error
+		j, err = job(reply.Job.ID)
+		require.NoError(t, err)
+		if j.State != "running" {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	require.Equal(t, "succeeded", j.State)
+	require.Equal(t, 11, j.Total)
+	require.Equal(t, 10, j.Done)
+	require.Equal(t, 1, j.Placeholders, "xkcd has no comic 404")
+	stored := stats(t)
+	require.Equal(t, 10, stored.ComicsFetched)
+	require.Equal(t, 1, stored.ComicsNotFound)
+
+	prepare(t)
+}
+
+func TestReindex(t *testing.T) {
+	prepare(t)
+	token := login(t)
+	start := func(path, body string) UpdateJob {
+		req, err := http.NewRequest(http.MethodPost, address+path, bytes.NewBufferString(body))
+		require.NoError(t, err, "cannot make request")
+		req.Header.Add("Authorization", "Token "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err, "could not start job")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		var reply UpdateReply
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+		require.Equal(t, "started", reply.Status)
+		for {
+			j, err := job(reply.Job.ID)
+			require.NoError(t, err)
+			if j.State != "running" {
+				return j
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+
+	j := start("/api/db/refetch", `{"ranges": [{"from": 1, "to": 5}]}`)
+	require.Equal(t, "succeeded", j.State)
+	require.Equal(t, 5, j.Done)
+
+	j = start("/api/db/reindex", "")
+	require.Equal(t, "reindex", j.Trigger)
+	require.Equal(t, "succeeded", j.State)
+	require.Equal(t, 0, j.Total, "comics are stored with the current words version")
+	require.Equal(t, 5, stats(t).ComicsFetched)
+
+	prepare(t)
+}
+
+func TestFailures(t *testing.T) {
+	resp, err := client.Get(address + "/api/db/failures?limit=5")
+	require.NoError(t, err, "could not list failures")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var reply struct {
+		Failures []struct {
+			ID       int    `json:"id"`
+			Class    string `json:"class"`
+			Attempts int    `json:"attempts"`
+		} `json:"failures"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+	require.LessOrEqual(t, len(reply.Failures), 5)
+	for _, f := range reply.Failures {
+		require.Contains(t, []string{"fetch", "normalize", "store"}, f.Class)
+		require.Positive(t, f.Attempts)
+	}
+
+	resp, err = client.Get(address + "/api/db/failures?limit=-1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestEmptyDB(t *testing.T) {
 	prepare(t)
 }
@@ -33,7 +265,7 @@ func TestUpdate(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(3)
 	var err1, err2, err3 error
-	var res1, res2 int
+	var res1, res2 string
 	var res3 string
 	token := login(t)
 	go func() {
@@ -54,13 +286,14 @@ func TestUpdate(t *testing.T) {
 	require.NoError(t, err2, "error from update")
 	require.NoError(t, err3, "erorr from status")
 	require.True(t,
-		res1 == http.StatusOK && res2 == http.StatusAccepted ||
-			res2 == http.StatusOK && res1 == http.StatusAccepted,
-		"wrong statuses from concurrent updates, expect ok && accepted",
+		res1 == "started" && res2 == "already_running" ||
+			res2 == "started" && res1 == "already_running",
+		"wrong statuses from concurrent updates, expect started && already_running",
 	)
 	require.Equal(t, "running", res3, "need running status while update")
 	st := stats(t)
-	require.Equal(t, st.ComicsTotal, st.ComicsFetched)
+	require.Equal(t, st.ComicsTotal, st.ComicsFetched+st.ComicsNotFound)
+	require.True(t, st.ComicsNotFound > 0, "xkcd has no comic 404")
 	require.True(t, st.ComicsTotal > 3000, "there are more than 3000 comics in XKCD")
 	require.True(t, 1000 < st.WordsTotal, "not enough total words in DB")
 	require.True(t, 100 < st.WordsUnique, "not enough unique words in DB")
@@ -93,6 +326,8 @@ func prepare(t *testing.T) {
 
 	updateStats := stats(t)
 	require.Equal(t, 0, updateStats.ComicsFetched)
+	require.Equal(t, 0, updateStats.ComicsNotFound)
+	require.Equal(t, 0, updateStats.ComicsFailed)
 	require.True(t, updateStats.ComicsTotal > 3000, "there are more than 3000 comics in XKCD")
 	require.Equal(t, 0, updateStats.WordsTotal)
 	require.Equal(t, 0, updateStats.WordsUnique)
@@ -100,19 +335,53 @@ func prepare(t *testing.T) {
 	require.Equal(t, "idle", updateStatus, err)
 }
 
+// update starts an update job and waits for it to finish.
 // this must not contain t because it runs in a waited goroutine
-func update(token string) (int, error) {
+func update(token string) (string, error) {
 	req, err := http.NewRequest(http.MethodPost, address+"/api/db/update", nil)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 	req.Header.Add("Authorization", "Token "+token)
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
-	resp.Body.Close()
-	return resp.StatusCode, nil
+	defer resp.Body.Close()
+	if http.StatusAccepted != resp.StatusCode {
+		return "", fmt.Errorf("http status: %v", resp.Status)
+	}
+	var reply UpdateReply
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("could not decode: %v", err)
+	}
+	for {
+		j, err := job(reply.Job.ID)
+		if err != nil {
+			return "", err
+		}
+		if j.State != "running" {
+			return reply.Status, nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// this must not contain t because it runs in a waited goroutine
+func job(id int64) (UpdateJob, error) {
+	resp, err := client.Get(fmt.Sprintf("%s/api/db/jobs/%d", address, id))
+	if err != nil {
+		return UpdateJob{}, err
+	}
+	defer resp.Body.Close()
+	if http.StatusOK != resp.StatusCode {
+		return UpdateJob{}, fmt.Errorf("http status: %v", resp.Status)
+	}
+	var j UpdateJob
+	if err = json.NewDecoder(resp.Body).Decode(&j); err != nil {
+		return UpdateJob{}, fmt.Errorf("could not decode: %v", err)
+	}
+	return j, nil
 }
 
 // this must not contain t because it runs in a waited goroutine
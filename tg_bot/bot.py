@@ -283,11 +283,16 @@ async def cmd_search(update: Update, context: ContextTypes.DEFAULT_TYPE) -> None
     data = safe_json(resp)
     comics = data.get("comics") or []
     if not comics:
-        await update.message.reply_text("no results")
+        suggestions = data.get("suggestions") or []
+        if suggestions:
+            await update.message.reply_text(f"no results, did you mean: {suggestions[0]}?")
+        else:
+            await update.message.reply_text("no results")
         return
 
     url = (comics[0] or {}).get("url")
     cid = (comics[0] or {}).get("id")
+    title = (comics[0] or {}).get("title")
     if not url:
         await update.message.reply_text("search result has no url")
         return
@@ -316,6 +321,8 @@ async def cmd_search(update: Update, context: ContextTypes.DEFAULT_TYPE) -> None
     caption_lines: list[str] = []
     if cid is not None:
         caption_lines.append(f"id: {cid}")
+    if title:
+        caption_lines.append(str(title))
     caption_lines.append(str(url))
     caption_lines.append("")
     caption_lines.append("Пояснение:")